@@ -1,34 +1,97 @@
 package blockchain
 
 import (
-	"encoding/binary"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/burrow/execution/errors"
 )
 
+// RingSize matches Ethereum's BLOCKHASH semantics: only the last 256 blocks
+// are addressable by height.
+const RingSize = 256
+
+var zeroHash = make([]byte, 32)
+
+// Blockchain tracks the Iroha chain tip and a ring buffer of the last 256
+// committed block hashes, so the EVM's BLOCKHASH opcode can resolve real
+// Iroha block hashes instead of a synthesized value.
 type Blockchain struct {
+	mtx         sync.RWMutex
 	blockHeight uint64
 	blockTime   time.Time
+	hashes      [RingSize][32]byte
+	known       [RingSize]bool
 }
 
 func New() *Blockchain {
 	return &Blockchain{}
 }
 
+var (
+	instance     *Blockchain
+	instanceOnce sync.Once
+)
+
+// Instance returns the process-wide Blockchain shared by every EVM execution,
+// so that block hashes committed while handling one call remain visible to
+// the next.
+func Instance() *Blockchain {
+	instanceOnce.Do(func() {
+		instance = New()
+	})
+	return instance
+}
+
 func (b *Blockchain) LastBlockHeight() uint64 {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
 	return b.blockHeight
 }
 
 func (b *Blockchain) LastBlockTime() time.Time {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
 	return b.blockTime
 }
 
+// CommitBlock records the hash of a newly committed Iroha block, advancing
+// the chain tip and evicting whatever hash previously occupied that ring
+// slot. It is safe to call concurrently with BlockHash.
+func (b *Blockchain) CommitBlock(height uint64, hash []byte, blockTime time.Time) error {
+	if len(hash) != 32 {
+		return errors.Errorf(errors.Codes.InvalidBlockNumber, "block hash must be 32 bytes, got %d", len(hash))
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	slot := height % RingSize
+	copy(b.hashes[slot][:], hash)
+	b.known[slot] = true
+	if height >= b.blockHeight {
+		b.blockHeight = height
+		b.blockTime = blockTime
+	}
+	return nil
+}
+
+// BlockHash returns the hash of the block committed at the given height.
+// Following Ethereum's BLOCKHASH semantics, heights above the chain tip, or
+// 256 or more blocks below it, resolve to the zero hash rather than an
+// error.
 func (b *Blockchain) BlockHash(height uint64) ([]byte, error) {
-	if height > b.blockHeight {
-		return nil, errors.Codes.InvalidBlockNumber
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	if height > b.blockHeight || b.blockHeight-height >= RingSize {
+		return zeroHash, nil
+	}
+	slot := height % RingSize
+	if !b.known[slot] {
+		return zeroHash, nil
 	}
-	bs := make([]byte, 32)
-	binary.BigEndian.PutUint64(bs[24:], height)
-	return bs, nil
+	hash := make([]byte, 32)
+	copy(hash, b.hashes[slot][:])
+	return hash, nil
 }
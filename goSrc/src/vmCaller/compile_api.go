@@ -0,0 +1,93 @@
+package main
+
+//typedef const char const_char;
+import "C"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	"vmCaller/compiler"
+	vm "vmCaller/evm"
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// metadataHashFromHex decodes a hex-encoded metadata digest, as produced by
+// compiler.Contract.MetadataHash, into the fixed-size key SetMetadata/
+// GetMetadata index by.
+func metadataHashFromHex(s string) (acmstate.MetadataHash, error) {
+	var metahash acmstate.MetadataHash
+	if s == "" {
+		return metahash, fmt.Errorf("empty metadata hash")
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return metahash, err
+	}
+	copy(metahash[:], raw)
+	return metahash, nil
+}
+
+//export VmCompile
+func VmCompile(source, language, optimizerRuns *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+
+	runs, err := strconv.Atoi(C.GoString(optimizerRuns))
+	if err != nil {
+		runs = 0
+	}
+
+	result, err := compiler.Compile(C.GoString(source), C.GoString(language), runs)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error compiling source: %s", err.Error()))
+	}
+
+	worldState := vm.NewIrohaState(storage)
+	for name, contract := range result.Contracts {
+		metahash, err := metadataHashFromHex(contract.MetadataHash)
+		if err != nil {
+			continue // no CBOR trailer in this contract's bytecode, nothing to persist
+		}
+		if err := worldState.SetMetadata(metahash, contract.Metadata()); err != nil {
+			return makeError(fmt.Sprintf("Error persisting metadata for contract %s: %s", name, err.Error()))
+		}
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error encoding compile result: %s", err.Error()))
+	}
+	return C.CString(string(encoded)), nil
+}
+
+//export VmGetMetadata
+func VmGetMetadata(address *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+
+	evmAddress, err := crypto.AddressFromHexString(C.GoString(address))
+	if err != nil {
+		return makeError("Invalid contract address")
+	}
+
+	worldState := vm.NewIrohaState(storage)
+	metahash, ok, err := worldState.GetContractMetadataHash(evmAddress)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error resolving metadata hash for %s: %s", evmAddress.String(), err.Error()))
+	}
+	if !ok {
+		return makeError(fmt.Sprintf("No metadata registered for contract %s", evmAddress.String()))
+	}
+
+	metadata, err := worldState.GetMetadata(metahash)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error fetching metadata for %s: %s", evmAddress.String(), err.Error()))
+	}
+	return C.CString(metadata), nil
+}
@@ -0,0 +1,103 @@
+// Package compiler shells out to a configured solc/vyper binary to turn
+// Solidity or Vyper source into deployable bytecode, so Iroha clients can
+// submit source directly instead of pre-compiled bytecode.
+package compiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+const (
+	// SolcEnvVar names the env var pointing at a solc binary; "solc" on PATH
+	// is used if it is unset.
+	SolcEnvVar = "VMCALLER_SOLC"
+	// VyperEnvVar names the env var pointing at a vyper binary; "vyper" on
+	// PATH is used if it is unset.
+	VyperEnvVar = "VMCALLER_VYPER"
+)
+
+// Contract is one named contract produced by a compile.
+type Contract struct {
+	Bytecode     string `json:"bytecode"`
+	ABI          string `json:"abi"`
+	MetadataHash string `json:"metadata_hash"`
+
+	// metadata is the raw compiler metadata blob the MetadataHash digests,
+	// kept unexported since it is persisted via IrohaState.SetMetadata
+	// rather than round-tripped through the compile response.
+	metadata string
+}
+
+// Result is the JSON shape returned to CGO callers: {contracts: {name: {...}}}.
+type Result struct {
+	Contracts map[string]Contract `json:"contracts"`
+}
+
+// Compile compiles source in the given language ("solidity", the default,
+// or "vyper") with the given optimizer run count, shelling out to whichever
+// binary SolcEnvVar/VyperEnvVar points at.
+func Compile(source, language string, optimizerRuns int) (*Result, error) {
+	switch language {
+	case "", "solidity":
+		return compileWith(SolcEnvVar, "solc", source, optimizerRuns)
+	case "vyper":
+		return compileWith(VyperEnvVar, "vyper", source, optimizerRuns)
+	default:
+		return nil, fmt.Errorf("unsupported language %q", language)
+	}
+}
+
+func compileWith(envVar, fallbackBinary, source string, optimizerRuns int) (*Result, error) {
+	binaryPath := os.Getenv(envVar)
+	if binaryPath == "" {
+		binaryPath = fallbackBinary
+	}
+
+	cmd := exec.Command(binaryPath, "--combined-json", "bin,abi,metadata",
+		"--optimize", "--optimize-runs", strconv.Itoa(optimizerRuns), "-")
+	cmd.Stdin = bytes.NewBufferString(source)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %v: %s", binaryPath, err, stderr.String())
+	}
+
+	return parseCombinedJSON(stdout.Bytes())
+}
+
+func parseCombinedJSON(raw []byte) (*Result, error) {
+	var combined struct {
+		Contracts map[string]struct {
+			Bin      string `json:"bin"`
+			Abi      string `json:"abi"`
+			Metadata string `json:"metadata"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(raw, &combined); err != nil {
+		return nil, fmt.Errorf("parsing compiler output: %v", err)
+	}
+
+	result := &Result{Contracts: make(map[string]Contract, len(combined.Contracts))}
+	for name, c := range combined.Contracts {
+		result.Contracts[name] = Contract{
+			Bytecode:     c.Bin,
+			ABI:          c.Abi,
+			MetadataHash: metadataHashFromBytecode(c.Bin),
+			metadata:     c.Metadata,
+		}
+	}
+	return result, nil
+}
+
+// Metadata returns the raw compiler metadata blob a Contract's MetadataHash
+// digests, for persisting alongside the hash.
+func (c Contract) Metadata() string {
+	return c.metadata
+}
@@ -0,0 +1,135 @@
+package compiler
+
+import "encoding/hex"
+
+// MetadataHashFromBytecode extracts the Swarm/IPFS-style metadata hash solc
+// embeds in a minimal CBOR map appended to the end of deployed bytecode, so
+// it can be used as the key under which the full metadata blob is stored.
+// It returns "" if hexBytecode has no recognisable CBOR trailer.
+func MetadataHashFromBytecode(hexBytecode string) string {
+	return metadataHashFromBytecode(hexBytecode)
+}
+
+func metadataHashFromBytecode(hexBytecode string) string {
+	raw, err := hex.DecodeString(hexBytecode)
+	if err != nil || len(raw) < 2 {
+		return ""
+	}
+
+	// The last two bytes are the big-endian length of the CBOR blob that
+	// immediately precedes them.
+	cborLen := int(raw[len(raw)-2])<<8 | int(raw[len(raw)-1])
+	if cborLen <= 0 || cborLen+2 > len(raw) {
+		return ""
+	}
+	cborStart := len(raw) - 2 - cborLen
+	value, _, err := decodeCBOR(raw[cborStart : len(raw)-2])
+	if err != nil {
+		return ""
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"ipfs", "bzzr1", "bzzr0"} {
+		if digest, ok := fields[key].([]byte); ok {
+			return hex.EncodeToString(digest)
+		}
+	}
+	return ""
+}
+
+// decodeCBOR decodes just enough of CBOR (RFC 7049) to read solc's metadata
+// trailer: maps keyed by text strings, whose values are byte strings, text
+// strings or unsigned integers. It returns the decoded value and the number
+// of bytes consumed.
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errEOF
+	}
+
+	major := data[0] >> 5
+	minor := data[0] & 0x1f
+
+	length, headerLen, err := cborLength(data, minor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0: // unsigned int
+		return length, headerLen, nil
+	case 2, 3: // byte string / text string
+		end := headerLen + int(length)
+		if end > len(data) {
+			return nil, 0, errEOF
+		}
+		if major == 3 {
+			return string(data[headerLen:end]), end, nil
+		}
+		return append([]byte{}, data[headerLen:end]...), end, nil
+	case 5: // map
+		offset := headerLen
+		result := make(map[string]interface{}, length)
+		for i := uint64(0); i < length; i++ {
+			key, keyLen, err := decodeCBOR(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += keyLen
+			val, valLen, err := decodeCBOR(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += valLen
+			if keyStr, ok := key.(string); ok {
+				result[keyStr] = val
+			}
+		}
+		return result, offset, nil
+	default:
+		return nil, 0, errUnsupportedCBORType
+	}
+}
+
+// cborLength decodes an argument encoded in the low 5 bits of a CBOR initial
+// byte (or the following 1/2/8 bytes for the 24/25/27 additional-info forms
+// solc's metadata actually uses) and returns it alongside the number of
+// bytes its encoding occupied, including the initial byte.
+func cborLength(data []byte, minor byte) (uint64, int, error) {
+	switch {
+	case minor < 24:
+		return uint64(minor), 1, nil
+	case minor == 24:
+		if len(data) < 2 {
+			return 0, 0, errEOF
+		}
+		return uint64(data[1]), 2, nil
+	case minor == 25:
+		if len(data) < 3 {
+			return 0, 0, errEOF
+		}
+		return uint64(data[1])<<8 | uint64(data[2]), 3, nil
+	case minor == 27:
+		if len(data) < 9 {
+			return 0, 0, errEOF
+		}
+		var v uint64
+		for i := 1; i <= 8; i++ {
+			v = v<<8 | uint64(data[i])
+		}
+		return v, 9, nil
+	default:
+		return 0, 0, errUnsupportedCBORType
+	}
+}
+
+type cborError string
+
+func (e cborError) Error() string { return string(e) }
+
+const (
+	errEOF                 = cborError("unexpected end of CBOR data")
+	errUnsupportedCBORType = cborError("unsupported CBOR type in metadata trailer")
+)
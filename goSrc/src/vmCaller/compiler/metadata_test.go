@@ -0,0 +1,41 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// buildCBORTrailer hand-encodes the minimal CBOR map solc appends:
+// {"ipfs": <34-byte digest>}, followed by the 2-byte big-endian length.
+func buildCBORTrailer(digest []byte) []byte {
+	var cbor []byte
+	cbor = append(cbor, 0xa1)       // map(1)
+	cbor = append(cbor, 0x64)       // text(4)
+	cbor = append(cbor, "ipfs"...)  // "ipfs"
+	cbor = append(cbor, 0x58, byte(len(digest))) // bytes(len)
+	cbor = append(cbor, digest...)
+
+	trailer := append([]byte{}, cbor...)
+	trailer = append(trailer, byte(len(cbor)>>8), byte(len(cbor)))
+	return trailer
+}
+
+func TestMetadataHashFromBytecode(t *testing.T) {
+	digest := make([]byte, 34)
+	for i := range digest {
+		digest[i] = byte(i)
+	}
+	bytecode := append([]byte{0x60, 0x80, 0x60, 0x40}, buildCBORTrailer(digest)...)
+
+	got := metadataHashFromBytecode(hex.EncodeToString(bytecode))
+	want := hex.EncodeToString(digest)
+	if got != want {
+		t.Fatalf("metadataHashFromBytecode() = %q, want %q", got, want)
+	}
+}
+
+func TestMetadataHashFromBytecodeNoTrailer(t *testing.T) {
+	if got := metadataHashFromBytecode("6080604052"); got != "" {
+		t.Fatalf("metadataHashFromBytecode() = %q, want empty for bytecode without a CBOR trailer", got)
+	}
+}
@@ -0,0 +1,182 @@
+package conformance
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"vmCaller/blockchain"
+	vm "vmCaller/evm"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/engine"
+	"github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+// mockEventWriter stands in for the cgo-backed IrohaState so vectors can run
+// against Burrow's engine without a live Iroha command executor behind them.
+type mockEventWriter struct{}
+
+func (mockEventWriter) StoreTxReceipt(address crypto.Address, data []byte, topics []binary.Word256) error {
+	return nil
+}
+
+// Result is the outcome of running a single Vector's transaction.
+type Result struct {
+	Vector Vector
+	Fork   string
+	Failed []string
+}
+
+// Run executes every Expectation in v.Post against v.Pre and v.Transaction,
+// returning one Result per fork with every mismatch found.
+func Run(v Vector) ([]Result, error) {
+	var results []Result
+	for fork, expected := range v.Post {
+		result, err := runOne(v, fork, expected)
+		if err != nil {
+			return nil, fmt.Errorf("vector %q (%s): %v", v.Name, fork, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runOne(v Vector, fork string, expected Expectation) (Result, error) {
+	state := acmstate.NewMemoryState()
+
+	for addrHex, account := range v.Pre {
+		if err := loadAccount(state, addrHex, account); err != nil {
+			return Result{}, err
+		}
+	}
+
+	sink := vm.NewIrohaEventSink(mockEventWriter{})
+	burrowEVM := evm.New(evm.Options{
+		Natives: vm.MustCreateNatives(),
+	})
+
+	from := crypto.MustAddressFromHexString(v.Transaction.From)
+	gas := mustParseUint64(v.Transaction.GasLimit)
+
+	var output []byte
+	var err error
+	if v.Transaction.To == "" {
+		callee := from
+		output, err = burrowEVM.Execute(state, blockchain.Instance(), sink, engine.CallParams{
+			Caller: from,
+			Callee: callee,
+			Input:  []byte{},
+			Value:  0,
+			Gas:    &gas,
+		}, mustDecodeHex(v.Transaction.Data))
+	} else {
+		to := crypto.MustAddressFromHexString(v.Transaction.To)
+		output, err = burrowEVM.Execute(state, blockchain.Instance(), sink, engine.CallParams{
+			Caller: from,
+			Callee: to,
+			Input:  mustDecodeHex(v.Transaction.Data),
+			Value:  0,
+			Gas:    &gas,
+		}, nil)
+	}
+	_ = output
+
+	result := Result{Vector: v, Fork: fork}
+	if err != nil {
+		result.Failed = append(result.Failed, fmt.Sprintf("execution error: %v", err))
+		return result, nil
+	}
+
+	for addrHex, wantAccount := range expected.PostState {
+		result.Failed = append(result.Failed, diffAccount(state, addrHex, wantAccount)...)
+	}
+	result.Failed = append(result.Failed, diffLogs(sink.Logs(), expected.Logs)...)
+
+	return result, nil
+}
+
+func loadAccount(state acmstate.ReaderWriter, addrHex string, account Account) error {
+	addr := crypto.MustAddressFromHexString(addrHex)
+	balance, err := strconv.ParseUint(account.Balance, 0, 64)
+	if err != nil {
+		return err
+	}
+	if err := state.UpdateAccount(&acm.Account{
+		Address:     addr,
+		Balance:     balance,
+		Permissions: vm.DefaultAccountPermissions,
+	}); err != nil {
+		return err
+	}
+	if account.Code != "" {
+		if err := native.InitCode(state, addr, mustDecodeHex(account.Code)); err != nil {
+			return err
+		}
+	}
+	for keyHex, valueHex := range account.Storage {
+		if err := state.SetStorage(addr, binary.LeftPadWord256(mustDecodeHex(keyHex)),
+			mustDecodeHex(valueHex)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffAccount(state acmstate.ReaderWriter, addrHex string, want Account) []string {
+	var mismatches []string
+	addr := crypto.MustAddressFromHexString(addrHex)
+	got, err := state.GetAccount(addr)
+	if err != nil {
+		return []string{fmt.Sprintf("account %s: %v", addrHex, err)}
+	}
+	if got == nil {
+		return []string{fmt.Sprintf("account %s: expected to exist in post-state", addrHex)}
+	}
+	if wantBalance, err := strconv.ParseUint(want.Balance, 0, 64); err == nil && wantBalance != got.Balance {
+		mismatches = append(mismatches, fmt.Sprintf("account %s: balance got %d want %d",
+			addrHex, got.Balance, wantBalance))
+	}
+	return mismatches
+}
+
+func diffLogs(got []vm.LoggedEvent, want []ExpectedLog) []string {
+	var mismatches []string
+	if len(got) != len(want) {
+		mismatches = append(mismatches, fmt.Sprintf("logs: got %d want %d", len(got), len(want)))
+		return mismatches
+	}
+	for i, w := range want {
+		if got[i].Data != w.Data {
+			mismatches = append(mismatches, fmt.Sprintf("log %d: data got %s want %s", i, got[i].Data, w.Data))
+		}
+	}
+	return mismatches
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(trimHexPrefix(s))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustParseUint64(s string) uint64 {
+	v, err := strconv.ParseUint(trimHexPrefix(s), 16, 64)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package conformance
+
+import "testing"
+
+func TestTrimHexPrefix(t *testing.T) {
+	cases := map[string]string{
+		"0x01": "01",
+		"0X01": "01",
+		"01":   "01",
+		"":     "",
+	}
+	for in, want := range cases {
+		if got := trimHexPrefix(in); got != want {
+			t.Errorf("trimHexPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRunReportsMissingPostStateAccount(t *testing.T) {
+	v := Vector{
+		Name: "missing-callee",
+		Pre: map[string]Account{
+			"0000000000000000000000000000000000000001": {Balance: "0x0"},
+		},
+		Transaction: Transaction{
+			From:     "0000000000000000000000000000000000000001",
+			To:       "0000000000000000000000000000000000000002",
+			GasLimit: "0x5208",
+		},
+		Post: map[string]Expectation{
+			"Istanbul": {
+				PostState: map[string]Account{
+					"0000000000000000000000000000000000000002": {Balance: "0x0"},
+				},
+			},
+		},
+	}
+
+	results, err := Run(v)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if len(results[0].Failed) == 0 {
+		t.Fatalf("expected mismatches for a callee account that was never created, got none")
+	}
+}
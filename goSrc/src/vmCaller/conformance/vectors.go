@@ -0,0 +1,103 @@
+// Package conformance runs the vmCaller EVM (Burrow's engine plus
+// IrohaEventSink) against an external corpus of go-ethereum style
+// GeneralStateTests vectors, to catch regressions in the Burrow-to-Iroha
+// translation layer that ad-hoc smoke tests would miss.
+//
+// The vector corpus itself is not vendored into this tree, and the tree has
+// no submodule or build target that fetches it. LoadVectors simply reads
+// whatever directory it is pointed at (see VectorsDir), so this package has
+// no opinion on how the corpus got there.
+package conformance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Account is the pre-state or expected post-state of a single account.
+type Account struct {
+	Balance string            `json:"balance"`
+	Code    string            `json:"code"`
+	Nonce   string            `json:"nonce"`
+	Storage map[string]string `json:"storage"`
+}
+
+// Transaction is the single call or contract creation a vector exercises.
+type Transaction struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Data     string `json:"data"`
+	GasLimit string `json:"gasLimit"`
+	Value    string `json:"value"`
+	Nonce    string `json:"nonce"`
+}
+
+// ExpectedLog is one log entry a vector expects the transaction to emit.
+type ExpectedLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// Expectation is the expected outcome of running Transaction against Pre.
+type Expectation struct {
+	PostState map[string]Account `json:"postState"`
+	Logs      []ExpectedLog      `json:"logs"`
+	GasUsed   string             `json:"gasUsed"`
+}
+
+// Vector is a single GeneralStateTests-style test case: a named scenario
+// with a shared pre-state and transaction, and one Expectation per fork it
+// was generated for.
+type Vector struct {
+	Name        string                 `json:"-"`
+	Pre         map[string]Account     `json:"pre"`
+	Transaction Transaction            `json:"transaction"`
+	Post        map[string]Expectation `json:"post"`
+}
+
+// LoadVectors reads every *.json file in dir as a map of vector name to
+// Vector, the layout go-ethereum's GeneralStateTests corpus uses.
+func LoadVectors(dir string) ([]Vector, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var vectors []Vector
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		raw, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var named map[string]Vector
+		if err := json.Unmarshal(raw, &named); err != nil {
+			return nil, err
+		}
+		for name, v := range named {
+			v.Name = name
+			vectors = append(vectors, v)
+		}
+	}
+	return vectors, nil
+}
+
+// VectorsDir resolves the corpus directory a harness run should use: the
+// VECTORS_DIR environment variable if set, else testdata/vectors.
+func VectorsDir() string {
+	if dir := os.Getenv("VECTORS_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("testdata", "vectors")
+}
+
+// Skip reports whether the conformance suite should short-circuit, for
+// developers who only want the fast unit-test suite.
+func Skip() bool {
+	return os.Getenv("SKIP_CONFORMANCE") == "1"
+}
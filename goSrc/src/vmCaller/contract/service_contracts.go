@@ -1,3 +1,17 @@
+// Package contract holds the original ServiceContract below: a balance/
+// transfer-only precompile built directly against vmCaller/api's cgo
+// wrappers, from the same pre-rewrite generation as state.State (see
+// state/vm_state.go's doc comment). Nothing constructs contract.ServiceContract
+// or calls contract.MustCreateNatives/IsNative - main.go wires up
+// evm.MustCreateNatives/evm.IsNative instead, whose ServiceContract (see
+// evm/native_contract.go) already covers everything this package's
+// ServiceContract does, plus the asset issuance/domain/role/permission
+// primitives (createAsset, addAssetQuantity/subtractAssetQuantity,
+// createDomain, appendRole/detachRole/createRole, grantPermission/
+// revokePermission) this package never grew. Extending this package's
+// ServiceContract to match would produce a second, still-unreachable copy of
+// natives the live one already exposes; new Iroha-backed precompiles belong
+// in evm/native_contract.go.
 package contract
 
 import (
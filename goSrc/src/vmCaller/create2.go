@@ -0,0 +1,112 @@
+package main
+
+//typedef const char const_char;
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"vmCaller/blockchain"
+	"vmCaller/compiler"
+	vm "vmCaller/evm"
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/engine"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/tmthrgd/go-hex"
+)
+
+// addressFromSalt computes a CREATE2 contract address per EIP-1014:
+// keccak256(0xff || caller || salt || keccak256(code))[12:].
+func addressFromSalt(caller crypto.Address, code, salt []byte) (address crypto.Address) {
+	preimage := make([]byte, 0, 1+crypto.AddressLength+32+32)
+	preimage = append(preimage, 0xff)
+	preimage = append(preimage, caller.Bytes()...)
+	preimage = append(preimage, salt...)
+	codeHash := crypto.Keccak256(code)
+	preimage = append(preimage, codeHash...)
+
+	hash := crypto.Keccak256(preimage)
+	copy(address[:], hash[len(hash)-crypto.AddressLength:])
+	return
+}
+
+//export VmCall2
+func VmCall2(input, caller, salt *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+	iroha.Caller = C.GoString(caller)
+
+	worldState := vm.NewIrohaState(storage)
+	evmCaller := native.AddressFromName(C.GoString(caller))
+	inputBytes := hex.MustDecodeString(C.GoString(input))
+	saltBytes := hex.MustDecodeString(C.GoString(salt))
+
+	engine := EngineWrapper{
+		engine:    burrowEVM,
+		state:     worldState,
+		eventSink: vm.NewIrohaEventSink(worldState),
+	}
+
+	output, err := engine.NewContract2(evmCaller, inputBytes, saltBytes)
+	if err != nil {
+		return makeError(err.Error())
+	}
+	return C.CString(output), nil
+}
+
+// NewContract2 deploys code at the CREATE2 address derived from caller,
+// code and salt, exactly as NewContract deploys at the CREATE (nonce-based)
+// address, so counterfactual deployments and factory patterns can
+// pre-compute where a contract will land before it exists.
+func (w *EngineWrapper) NewContract2(caller crypto.Address, code []byte, salt []byte) (string, error) {
+	var gas uint64 = 1000000
+
+	callee := addressFromSalt(caller, code, salt)
+
+	calleeAccount, err := w.state.GetAccount(callee)
+	if err != nil {
+		return "", err
+	}
+	if calleeAccount != nil {
+		return "", fmt.Errorf("Account already exists at address %s", callee.String())
+	}
+
+	if err := w.state.UpdateAccount(&acm.Account{
+		Address:     callee,
+		Permissions: vm.DefaultAccountPermissions,
+	}); err != nil {
+		return "", fmt.Errorf("Error creating account at address %s: %s",
+			callee.String(), err.Error())
+	}
+
+	params := engine.CallParams{
+		Caller: caller,
+		Callee: callee,
+		Input:  []byte{},
+		Value:  0,
+		Gas:    &gas,
+	}
+	output, err := w.engine.Execute(w.state, blockchain.Instance(), w.eventSink, params, code)
+	if err != nil {
+		return "", fmt.Errorf("Error deploying smart contract at address %s: %s",
+			callee.String(), err.Error())
+	}
+
+	if err := native.InitCode(w.state, callee, output); err != nil {
+		return "", fmt.Errorf("Error initializing contract code at address %s: %s",
+			callee.String(), err.Error())
+	}
+
+	if irohaState, ok := w.state.(*vm.IrohaState); ok {
+		if metahash, err := metadataHashFromHex(compiler.MetadataHashFromBytecode(hex.EncodeToString(output))); err == nil {
+			if err := irohaState.SetContractMetadataHash(callee, metahash); err != nil {
+				fmt.Println("Error associating metadata hash with contract", callee.String(), ":", err.Error())
+			}
+		}
+	}
+
+	return callee.String(), nil
+}
@@ -0,0 +1,172 @@
+package main
+
+//typedef const char const_char;
+import "C"
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	vm "vmCaller/evm"
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/tmthrgd/go-hex"
+)
+
+// DelegationAuthAccount is the Iroha account under which EIP-7702
+// authorization nonces are tracked, one detail key per authority address,
+// independent of any single delegated contract.
+const DelegationAuthAccount = "evm_delegation_auth@evm"
+
+// delegationPrefix marks an EIP-7702 "set code" designator: 0xef0100
+// followed by the 20-byte address of the contract whose code should run in
+// the delegating account's own storage context.
+var delegationPrefix = []byte{0xef, 0x01, 0x00}
+
+var delegationDesignatorLength = len(delegationPrefix) + crypto.AddressLength
+
+// isDelegationDesignator reports whether code is an EIP-7702 delegation
+// designator rather than ordinary EVM bytecode.
+func isDelegationDesignator(code []byte) bool {
+	return len(code) == delegationDesignatorLength && bytes.Equal(code[:len(delegationPrefix)], delegationPrefix)
+}
+
+// delegationTarget extracts the contract address an EIP-7702 designator
+// points at. Callers must check isDelegationDesignator first.
+func delegationTarget(code []byte) crypto.Address {
+	var addr crypto.Address
+	copy(addr[:], code[len(delegationPrefix):])
+	return addr
+}
+
+func authorityNonceKey(authority crypto.Address) string {
+	return fmt.Sprintf("nonce_%s", authority.String())
+}
+
+//export VmAuthorize
+func VmAuthorize(caller, authority, delegate, nonce, signature *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+	iroha.Caller = C.GoString(caller)
+
+	worldState := vm.NewIrohaState(storage)
+
+	evmAuthority := native.AddressFromName(C.GoString(authority))
+	evmDelegate, err := crypto.AddressFromHexString(C.GoString(delegate))
+	if err != nil {
+		return makeError("Invalid delegate address")
+	}
+	if vm.IsNative(evmDelegate.String()) {
+		return makeError(fmt.Sprintf("The delegate address %s is reserved for a native contract and cannot be delegated to",
+			evmDelegate.String()))
+	}
+
+	requestedNonce, err := strconv.ParseUint(C.GoString(nonce), 10, 64)
+	if err != nil {
+		return makeError("Invalid nonce")
+	}
+
+	currentNonce, err := authorizationNonce(evmAuthority)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error reading authorization nonce for %s: %s", evmAuthority.String(), err.Error()))
+	}
+	if requestedNonce != currentNonce {
+		return makeError(fmt.Sprintf("Stale authorization nonce for %s: expected %d, got %d",
+			evmAuthority.String(), currentNonce, requestedNonce))
+	}
+
+	authorityAccount, err := worldState.GetAccount(evmAuthority)
+	if err != nil {
+		return makeError(fmt.Sprintf("Error getting account at address %s: %s", evmAuthority.String(), err.Error()))
+	}
+	if authorityAccount == nil {
+		return makeError(fmt.Sprintf("Authority account does not exist at address %s", evmAuthority.String()))
+	}
+
+	if err := verifyAuthorization(authorityAccount, evmDelegate, requestedNonce, C.GoString(signature)); err != nil {
+		return makeError(fmt.Sprintf("Invalid authorization signature: %s", err.Error()))
+	}
+
+	// EIP-7702 only ever lets an authorization overwrite an authority's code
+	// when that code is empty or is itself already a delegation designator;
+	// otherwise a valid authorization signature against the authority's own
+	// key would be enough to brick an already-deployed contract's bytecode.
+	if len(authorityAccount.EVMCode) != 0 && !isDelegationDesignator(authorityAccount.EVMCode) {
+		return makeError(fmt.Sprintf(
+			"Authority %s already has non-delegation code and cannot be authorized", evmAuthority.String()))
+	}
+
+	designator := append(append([]byte{}, delegationPrefix...), evmDelegate.Bytes()...)
+	if err := native.InitCode(worldState, evmAuthority, designator); err != nil {
+		return makeError(fmt.Sprintf("Error storing delegation designator for %s: %s", evmAuthority.String(), err.Error()))
+	}
+
+	// A retried VmAuthorize call for the very same authorization tuple (the
+	// C ABI above gives no stronger exactly-once guarantee than Iroha's own
+	// command executor does) must not advance the nonce twice, so this sets
+	// the idempotency key makeProtobufCmdAndExecute otherwise only derives
+	// by hashing the marshaled command plus a monotonic nonce - a derived
+	// key would treat a genuine retry as a brand new call and let it
+	// through a second time. Keying on (evmAuthority, requestedNonce) makes
+	// two attempts at authorizing the same nonce share one cached result
+	// instead of both reaching Iroha_ProtoCommandExecutorExecute.
+	iroha.NextIdempotencyKey = fmt.Sprintf("vm_authorize_%s_%d", evmAuthority.String(), requestedNonce)
+	if err := iroha.SetAccountDetail(DelegationAuthAccount, authorityNonceKey(evmAuthority),
+		strconv.FormatUint(requestedNonce+1, 10)); err != nil {
+		return makeError(fmt.Sprintf("Error advancing authorization nonce for %s: %s", evmAuthority.String(), err.Error()))
+	}
+
+	return C.CString(evmAuthority.String()), nil
+}
+
+func authorizationNonce(authority crypto.Address) (uint64, error) {
+	raw, err := iroha.GetAccountDetailByKey(DelegationAuthAccount, authorityNonceKey(authority))
+	if err != nil || raw == "" {
+		return 0, err
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+// verifyAuthorization checks signature against the EIP-7702 authorization
+// tuple (chain_id, address, nonce) using the authority's registered public
+// key. Full EIP-7702 recovers the signer's address directly from the
+// signature instead of verifying against a known key, but this bridge
+// already requires the authority to be a known, funded Iroha-mapped account
+// before it can be delegated from, so verifying against its registered key
+// gives the same guarantee without pulling in a separate secp256k1 recovery
+// dependency.
+func verifyAuthorization(authorityAccount *acm.Account, delegate crypto.Address, nonce uint64, signatureHex string) error {
+	sigBytes, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %v", err)
+	}
+	sig, err := crypto.SignatureFromBytes(sigBytes, authorityAccount.PublicKey.CurveType)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %v", err)
+	}
+
+	msg := authorizationMessage(delegate, nonce)
+	if !authorityAccount.PublicKey.Verify(msg, sig) {
+		return fmt.Errorf("signature does not match authority's registered key")
+	}
+	return nil
+}
+
+// authorizationMessage builds the digest an EIP-7702 authorization tuple
+// (chain_id, address, nonce) is signed over.
+func authorizationMessage(delegate crypto.Address, nonce uint64) []byte {
+	var nonceBytes [8]byte
+	for i := 0; i < 8; i++ {
+		nonceBytes[7-i] = byte(nonce >> (8 * uint(i)))
+	}
+	msg := make([]byte, 0, 1+8+crypto.AddressLength+8)
+	msg = append(msg, 0x05) // EIP-7702 MAGIC
+	msg = append(msg, make([]byte, 8)...)
+	msg = append(msg, delegate.Bytes()...)
+	msg = append(msg, nonceBytes[:]...)
+	return crypto.Keccak256(msg)
+}
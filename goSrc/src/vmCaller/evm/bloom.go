@@ -0,0 +1,68 @@
+package evm
+
+import (
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// BloomByteLength is the width of an Ethereum-style 2048-bit bloom filter.
+const BloomByteLength = 256
+
+// Bloom is a 2048-bit Ethereum-style bloom filter over logged addresses and
+// topics, letting EthService prune candidate blocks before decoding logs.
+type Bloom [BloomByteLength]byte
+
+// AddAddress ORs the bits derived from a 20-byte address (left-padded to 32
+// bytes, as the EVM does) into the bloom.
+func (b *Bloom) AddAddress(addr crypto.Address) {
+	var padded [32]byte
+	copy(padded[32-crypto.AddressLength:], addr.Bytes())
+	b.add(padded[:])
+}
+
+// AddTopic ORs the bits derived from a 32-byte log topic into the bloom.
+func (b *Bloom) AddTopic(topic binary.Word256) {
+	b.add(topic.Bytes())
+}
+
+// add sets the three bits the Ethereum bloom algorithm derives from the
+// Keccak-256 hash of a 32-byte input: bytes (0,1), (2,3) and (4,5) of the
+// hash, each read big-endian and masked with 0x07FF to give a bit index.
+func (b *Bloom) add(data []byte) {
+	hash := crypto.Keccak256(data)
+	for i := 0; i < 6; i += 2 {
+		bit := (uint(hash[i])<<8 | uint(hash[i+1])) & 0x07FF
+		b[BloomByteLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Merge ORs other into b, the way a block bloom is built up from the blooms
+// of every transaction within it.
+func (b *Bloom) Merge(other Bloom) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+// Test reports whether every bit set in query is also set in b, i.e. whether
+// b could possibly contain a log matching whatever addresses/topics query
+// was built from.
+func (b Bloom) Test(query Bloom) bool {
+	for i := range b {
+		if b[i]&query[i] != query[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ComputeLogBloom computes the bloom filter contribution of a single log:
+// its address and each of its topics.
+func ComputeLogBloom(address crypto.Address, topics []binary.Word256) Bloom {
+	var bloom Bloom
+	bloom.AddAddress(address)
+	for _, topic := range topics {
+		bloom.AddTopic(topic)
+	}
+	return bloom
+}
@@ -1,6 +1,8 @@
 package evm
 
 import (
+	"encoding/hex"
+
 	"github.com/hyperledger/burrow/binary"
 	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/burrow/execution/errors"
@@ -13,8 +15,18 @@ type EventWriter interface {
 	StoreTxReceipt(address crypto.Address, hex_data []byte, topics []binary.Word256) error
 }
 
+// CallTrace is the minimal record of a call kept so that debug_traceTransaction
+// can be built on top of it later, rather than Call events being dropped.
+type CallTrace struct {
+	Event     *exec.CallEvent
+	Exception *errors.Exception
+}
+
 type IrohaEventSink struct {
 	irohaState EventWriter
+	bloom      Bloom
+	logs       []LoggedEvent
+	calls      []CallTrace
 }
 
 func NewIrohaEventSink(state EventWriter) *IrohaEventSink {
@@ -24,10 +36,41 @@ func NewIrohaEventSink(state EventWriter) *IrohaEventSink {
 }
 
 func (ies *IrohaEventSink) Call(call *exec.CallEvent, exception *errors.Exception) error {
+	ies.calls = append(ies.calls, CallTrace{Event: call, Exception: exception})
 	return nil
 }
 
 func (ies *IrohaEventSink) Log(log *exec.LogEvent) error {
-	err := ies.irohaState.StoreTxReceipt(log.Address, log.Data, log.Topics)
-	return err
+	logBloom := ComputeLogBloom(log.Address, log.Topics)
+	ies.bloom.Merge(logBloom)
+
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = hex.EncodeToString(topic.Bytes())
+	}
+	ies.logs = append(ies.logs, LoggedEvent{
+		Address: log.Address.String(),
+		Data:    hex.EncodeToString(log.Data),
+		Topics:  topics,
+		Bloom:   hex.EncodeToString(logBloom[:]),
+	})
+
+	return ies.irohaState.StoreTxReceipt(log.Address, log.Data, log.Topics)
+}
+
+// Bloom returns the bloom filter accumulated from every Log event seen so
+// far, for merging into the block-level bloom index.
+func (ies *IrohaEventSink) Bloom() Bloom {
+	return ies.bloom
+}
+
+// Logs returns every log recorded so far, ready to be indexed alongside the
+// block bloom.
+func (ies *IrohaEventSink) Logs() []LoggedEvent {
+	return ies.logs
+}
+
+// Calls returns every call event recorded so far.
+func (ies *IrohaEventSink) Calls() []CallTrace {
+	return ies.calls
 }
@@ -49,4 +49,15 @@ func TestLog(t *testing.T) {
 		store.data[0],
 		hex.MustDecodeString("0123456789ABCDEF0123456789ABCDEF012345670000000000000000000000000000000000000000000000000000000000000001ABCDEF"),
 	)
+
+	// The same Log call must also round-trip into the sink's own
+	// bloom/logs accumulators, which persistLogIndex later indexes.
+	require.Len(t, sink.Logs(), 1)
+	logged := sink.Logs()[0]
+	assert.Equal(t, addr.String(), logged.Address)
+	assert.Equal(t, "abcdef", logged.Data)
+
+	wantBloom := ComputeLogBloom(addr, topics)
+	assert.Equal(t, wantBloom, sink.Bloom())
+	assert.Equal(t, hex.EncodeToString(wantBloom[:]), logged.Bloom)
 }
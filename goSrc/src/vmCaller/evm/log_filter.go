@@ -0,0 +1,103 @@
+package evm
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// LogFilter is an eth_getLogs-style query over the block-bloom/log index
+// PersistBlockIndex maintains: every block in [FromBlock, ToBlock] whose
+// bloom filter could possibly match is decoded and scanned, so callers never
+// pay to decode a block that provably has no matching log. Only values every
+// match must carry (a single-entry address list or topic position) take part
+// in the bloom test; a list of alternatives is checked against the decoded
+// logs alone. This lives here
+// rather than in the iroha package (closer to the cgo entry points
+// Iroha_StoreLog already goes through) because it queries LoadBlockBloom/
+// LoadBlockLogs, which are themselves evm-package helpers built on top of
+// iroha.GetAccountDetailByKey — iroha importing evm back would cycle.
+type LogFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Addresses []crypto.Address
+	Topics    [][]binary.Word256
+}
+
+// Query runs the filter and returns every matching LoggedEvent in ascending
+// block order.
+func (f *LogFilter) Query() ([]LoggedEvent, error) {
+	var requiredAddresses []crypto.Address
+	if len(f.Addresses) == 1 {
+		requiredAddresses = f.Addresses
+	}
+	var requiredTopics [][]binary.Word256
+	for _, position := range f.Topics {
+		if len(position) == 1 {
+			requiredTopics = append(requiredTopics, position)
+		}
+	}
+	query := QueryBloom(requiredAddresses, requiredTopics)
+
+	var matches []LoggedEvent
+	for height := f.FromBlock; height <= f.ToBlock; height++ {
+		bloom, err := LoadBlockBloom(height)
+		if err != nil {
+			return nil, err
+		}
+		if !bloom.Test(query) {
+			continue
+		}
+		logs, err := LoadBlockLogs(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if logMatchesFilter(log, f.Addresses, f.Topics) {
+				matches = append(matches, log)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// logMatchesFilter reports whether log satisfies an eth_getLogs-style
+// address/topic filter: an empty addresses list matches any address, and
+// each topics[i] is itself a list of acceptable values for position i (an
+// empty position matches anything).
+func logMatchesFilter(log LoggedEvent, addresses []crypto.Address, topics [][]binary.Word256) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, addr := range addresses {
+			if strings.EqualFold(log.Address, addr.String()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, position := range topics {
+		if len(position) == 0 {
+			continue
+		}
+		matched := false
+		for _, topic := range position {
+			if strings.EqualFold(log.Topics[i], hex.EncodeToString(topic.Bytes())) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
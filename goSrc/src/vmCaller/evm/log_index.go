@@ -0,0 +1,181 @@
+package evm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// LogIndexAccount is the Iroha account under which per-block bloom filters
+// and log records are indexed, independent of which contract emitted them.
+const LogIndexAccount = "evm_log_index@evm"
+
+// LoggedEvent is the minimal persisted record of one EVM log, keyed by its
+// block height so EthService.LogsFilter can reconstruct it once bloom
+// filtering has pruned the candidate set down.
+type LoggedEvent struct {
+	BlockHeight uint64   `json:"blockHeight"`
+	Address     string   `json:"address"`
+	Data        string   `json:"data"`
+	Topics      []string `json:"topics"`
+	Bloom       string   `json:"bloom"`
+}
+
+func blockBloomKey(height uint64) string {
+	return fmt.Sprintf("bloom_%d", height)
+}
+
+func blockLogsKey(height uint64) string {
+	return fmt.Sprintf("logs_%d", height)
+}
+
+func txReceiptKey(txHash []byte) string {
+	return fmt.Sprintf("tx_%s", hex.EncodeToString(txHash))
+}
+
+// TxReceipt is this bridge's record of one VmCall's outcome, indexed by the
+// synthetic transaction hash main.go derives for it (see computeTxHash),
+// since Iroha itself has no notion of an Ethereum-style transaction hash.
+// Status is always 1 (success): persistLogIndex only runs once VmCall's
+// engine call has already returned without error, so a receipt is never
+// persisted for a reverted call. TxIndex is always 0, as this bridge does
+// not currently track how many VmCalls land in the same Iroha block.
+type TxReceipt struct {
+	BlockHeight     uint64        `json:"blockHeight"`
+	TxIndex         uint64        `json:"txIndex"`
+	Status          uint64        `json:"status"`
+	From            string        `json:"from"`
+	To              string        `json:"to"`              // empty for a contract-creation call
+	ContractAddress string        `json:"contractAddress"` // non-empty only for a contract-creation call
+	Logs            []LoggedEvent `json:"logs"`
+	// PrivateStateRoot is set only for a VmCallPrivate call: the hex-encoded
+	// result of PrivateStateRoot for the call's target account, so a
+	// recipient can check its own PrivateState ended up matching what this
+	// receipt recorded (see PrivateState's doc comment for why this only
+	// covers the one account a private call touched, not a full root).
+	PrivateStateRoot string `json:"privateStateRoot,omitempty"`
+}
+
+// PersistTxReceipt indexes receipt under txHash, alongside the per-block
+// index PersistBlockIndex maintains, so a receipt can be looked up directly
+// by hash instead of scanning every block it might be in.
+func PersistTxReceipt(txHash []byte, receipt TxReceipt) error {
+	encoded, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	return iroha.SetAccountDetail(LogIndexAccount, txReceiptKey(txHash), hex.EncodeToString(encoded))
+}
+
+// LoadTxReceipt returns the receipt PersistTxReceipt indexed for txHash, or
+// nil if none has been recorded.
+func LoadTxReceipt(txHash []byte) (*TxReceipt, error) {
+	raw, err := iroha.GetAccountDetailByKey(LogIndexAccount, txReceiptKey(txHash))
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	decoded, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var receipt TxReceipt
+	if err := json.Unmarshal(decoded, &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// PersistBlockIndex merges sinkBloom into whatever bloom is already indexed
+// for height, appends the newly logged events, and writes both back to
+// Iroha account details.
+func PersistBlockIndex(height uint64, sinkBloom Bloom, newLogs []LoggedEvent) error {
+	existingBloom, err := loadBlockBloom(height)
+	if err != nil {
+		return err
+	}
+	existingBloom.Merge(sinkBloom)
+	if err := iroha.SetAccountDetail(LogIndexAccount, blockBloomKey(height), hex.EncodeToString(existingBloom[:])); err != nil {
+		return err
+	}
+
+	if len(newLogs) == 0 {
+		return nil
+	}
+	for i := range newLogs {
+		newLogs[i].BlockHeight = height
+	}
+
+	existingLogs, err := loadBlockLogs(height)
+	if err != nil {
+		return err
+	}
+	existingLogs = append(existingLogs, newLogs...)
+	encoded, err := json.Marshal(existingLogs)
+	if err != nil {
+		return err
+	}
+	return iroha.SetAccountDetail(LogIndexAccount, blockLogsKey(height), hex.EncodeToString(encoded))
+}
+
+func loadBlockBloom(height uint64) (Bloom, error) {
+	var bloom Bloom
+	raw, err := iroha.GetAccountDetailByKey(LogIndexAccount, blockBloomKey(height))
+	if err != nil || raw == "" {
+		return bloom, err
+	}
+	decoded, err := hex.DecodeString(raw)
+	if err != nil {
+		return bloom, err
+	}
+	copy(bloom[:], decoded)
+	return bloom, nil
+}
+
+func loadBlockLogs(height uint64) ([]LoggedEvent, error) {
+	raw, err := iroha.GetAccountDetailByKey(LogIndexAccount, blockLogsKey(height))
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	decoded, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var logs []LoggedEvent
+	if err := json.Unmarshal(decoded, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// LoadBlockLogs returns the logs indexed for a single block height, used by
+// EthService.LogsFilter once bloom pruning has selected it as a candidate.
+func LoadBlockLogs(height uint64) ([]LoggedEvent, error) {
+	return loadBlockLogs(height)
+}
+
+// LoadBlockBloom returns the aggregate bloom filter indexed for a block
+// height, or the zero bloom if nothing has been logged at that height yet.
+func LoadBlockBloom(height uint64) (Bloom, error) {
+	return loadBlockBloom(height)
+}
+
+// QueryBloom builds the bloom filter an eth_getLogs-style address/topic
+// filter is matched against: a block is a candidate only if every address
+// and every topic value present in the filter also appears in its bloom.
+func QueryBloom(addresses []crypto.Address, topics [][]binary.Word256) Bloom {
+	var bloom Bloom
+	for _, addr := range addresses {
+		bloom.AddAddress(addr)
+	}
+	for _, position := range topics {
+		for _, topic := range position {
+			bloom.AddTopic(topic)
+		}
+	}
+	return bloom
+}
@@ -3,14 +3,49 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
+	"strconv"
 
 	"vmCaller/iroha"
 	"vmCaller/iroha_model"
+	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/burrow/execution/native"
 	"github.com/hyperledger/burrow/permission"
 )
 
+// ServiceContract is this bridge's ABI-driven precompile registry: each
+// native.Function below is dispatched by its Solidity 4-byte selector and
+// reflects its argument/return struct to do the ABI decode/encode, replacing
+// the fixed-address/magic-storage-slot dispatch state.State.GetStorage used
+// before (see state/vm_state.go's doc comment) — new Iroha-backed precompiles
+// should be added as a native.Function here, not to the legacy package.
+//
+// It is also the only ServiceContract on the live path: contract/
+// service_contracts.go's like-named var is a second, unreferenced
+// ServiceContract from the same pre-rewrite generation as state.State,
+// exposing only balance queries and transfers against vmCaller/api's cgo
+// wrappers. Asset issuance/management beyond transfer - mint/burn
+// (addAssetQuantity/subtractAssetQuantity below), domain creation
+// (createDomain), role assignment (appendRole/detachRole/createRole), and
+// permission management (grantPermission/revokePermission) - already exists
+// here, not there; see contract/service_contracts.go's doc comment for why
+// it isn't getting a second copy of the same natives.
+//
+// Functions whose misuse has chain-wide consequences (governance, asset
+// supply, peer membership, role/permission grants) are gated by their own
+// Iroha*-prefixed PermFlag (see permissions.go) instead of the blanket
+// permission.Call every function here used before, so an operator can grant
+// an account ordinary Call access without also handing it addPeer/
+// createRole/etc. Every other function keeps permission.Call: singling out
+// a plain read or asset transfer wouldn't buy anything a blanket Call grant
+// doesn't already cover.
+//
+// Every state-mutating function below also calls emitIrohaEvent (see
+// native_events.go) right after its ctx.Logger.Trace.Log line, on success
+// only, so the change it made is visible through eth_getLogs/transaction
+// receipts and not just through the trace log. There is no Solidity stub for
+// ServiceContract anywhere in this checkout to add the matching `event`
+// declarations to; the signature strings passed to emitIrohaEvent below are
+// this function's only record of each event's ABI until such a stub exists.
 var (
 	ServiceContract = native.New().MustContract("ServiceContract",
 		`* acmstate.ReaderWriter for bridging EVM state and Iroha state.
@@ -47,7 +82,7 @@ var (
 				* @param Key key of account
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaCreateAccount,
 			F:        createAccount,
 		},
 		native.Function{
@@ -57,7 +92,7 @@ var (
 				* @param Amount mount of asset to be added
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaModifyAssetSupply,
 			F:        addAssetQuantity,
 		},
 		native.Function{
@@ -67,7 +102,7 @@ var (
 				* @param Amount amount of asset to be subtracted
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaModifyAssetSupply,
 			F:        subtractAssetQuantity,
 		},
 		native.Function{
@@ -97,7 +132,7 @@ var (
 				* @param Quorum quorum value to be set
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaSetQuorum,
 			F:        setAccountQuorum,
 		},
 		native.Function{
@@ -147,7 +182,7 @@ var (
 				* @param Precision precision of created asset
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaCreateAsset,
 			F:        createAsset,
 		},
 		native.Function{
@@ -175,7 +210,7 @@ var (
 				* @param Role new role of the account
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaAppendRole,
 			F:        appendRole,
 		},
 		native.Function{
@@ -185,7 +220,7 @@ var (
 				* @param Role role of the account to be removed
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaDetachRole,
 			F:        detachRole,
 		},
 		native.Function{
@@ -195,7 +230,7 @@ var (
 				* @param PeerKey key of the new peer
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaAddPeer,
 			F:        addPeer,
 		},
 		native.Function{
@@ -204,7 +239,7 @@ var (
 				* @param PeerKey key of the peer to be removed
 				* @return 'true' if successful, 'false' otherwise
 				`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaRemovePeer,
 			F:        removePeer,
 		},
 		native.Function{
@@ -243,12 +278,36 @@ var (
 		},
 		native.Function{
 			Comment: `
-				* @notice Get transactions of the account
+				* @notice Get a page of transactions of the account
 				* @param Account account to be used
-				* @param TxPaginationMeta`,
+				* @param TxPaginationMeta
+				* @return the page's transactions as JSON, its TotalCount across every
+				* page, and - if there is a next page - a Cursor to pass to
+				* continueAccountTransactions instead of re-supplying
+				* FirstTxHash/FirstTxTime/FirstTxHeight by hand`,
 			PermFlag: permission.Call,
 			F:        getAccountTransactions,
 		},
+		native.Function{
+			Comment: `
+				* @notice Fetches the next page of a getAccountTransactions scan
+				* @param Cursor a Cursor returned by getAccountTransactions or a
+				* previous continueAccountTransactions call
+				* @return the next page, in the same shape as getAccountTransactions`,
+			PermFlag: permission.Call,
+			F:        continueAccountTransactions,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets the total number of transactions an account's history
+				* holds, without fetching a page of them - for a UI sizing a progress
+				* bar before it starts paging through getAccountTransactions/
+				* continueAccountTransactions
+				* @param Account account to be used
+				* @return the account's total transaction count`,
+			PermFlag: permission.Call,
+			F:        getAccountTransactionsCount,
+		},
 		native.Function{
 			Comment: `
 				* @notice Get pending transactions of the account
@@ -259,26 +318,38 @@ var (
 		native.Function{
 			Comment: `
 				* @notice Get account asset transactions of the account
-				* @param account Id 
+				* @param account Id
 				* @param asset Id
 				* @param TxPaginationMeta`,
 			PermFlag: permission.Call,
 			F:        getAccountAssetTransactions,
 		},
+		native.Function{
+			Comment: `
+				* @notice List an account's transactions touching a given asset with
+				* offset/page pagination, optional counterparty/commandType/amount/
+				* status filters, and a compact JSON response, instead of iroha's
+				* own cursor-only getAccountAssetTransactions
+				* @param QueryAccountAssetTransactionsArgs
+				* @return {total, page, pageSize, transactions:[{hash, timestamp,
+				* height, from, to, amount, assetId, commandType, status}]} as JSON`,
+			PermFlag: permission.Call,
+			F:        queryAccountAssetTransactions,
+		},
 		native.Function{
 			Comment: `
 				* @notice Grant Permission
-				* @param account  
+				* @param account
 				* @param permission`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaGrantPermission,
 			F:       grantPermission,
 		},
 		native.Function{
 			Comment: `
 				* @notice Revoke Permission
-				* @param account  
+				* @param account
 				* @param permission`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaRevokePermission,
 			F:       revokePermission,
 		},
 		native.Function{
@@ -299,14 +370,200 @@ var (
 			PermFlag: permission.Call,
 			F:       getTransactions,
 		},
+		native.Function{
+			Comment: `
+				* @notice Get a chunked, partial-failure-tolerant batch of transactions by
+				* hash, instead of getTransactions' single all-or-nothing query
+				* @param GetTransactionsBatchArgs
+				* @return {found: map[hash]transaction, missing: [hash,...], truncated} as JSON`,
+			PermFlag: permission.Call,
+			F:       getTransactionsBatch,
+		},
 		native.Function{
 			Comment: `
 				* @notice Create Role
 				* @param role name
 				* @param permissions`,
-			PermFlag: permission.Call,
+			PermFlag: IrohaCreateRole,
 			F:       createRole,
 		},
+		native.Function{
+			Comment: `
+				* @notice Transfers an asset from Src to Dst via a chain of intermediate
+				* accounts (Path, JSON array of account IDs), aborting up front if
+				* SendMax cannot satisfy DestMin. Iroha has no native asset-exchange
+				* command, so SendAsset must equal DestAsset; this only chains
+				* same-asset hops rather than performing a currency conversion.
+				* @param Src source account address
+				* @param Dst destination account address
+				* @param SendAsset asset ID debited from Src
+				* @param SendMax maximum amount debited from Src
+				* @param DestAsset asset ID credited to Dst (must equal SendAsset)
+				* @param DestMin minimum amount credited to Dst
+				* @param Path JSON array of intermediate account IDs
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        transferAssetPath,
+		},
+		native.Function{
+			Comment: `
+				* @notice Atomically transfers a certain amount of asset and sets a detail
+				* on the destination account, e.g. to record the reason for a transfer.
+				* Stops and returns an error before setting the detail if the transfer
+				* itself fails; see iroha.Tx for how much atomicity this actually gives.
+				* @param Src source account address
+				* @param Dst destination account address
+				* @param Description description of the transfer
+				* @param Asset asset ID
+				* @param Amount amount to transfer
+				* @param Key key for the added detail on Dst
+				* @param Value value of the added detail on Dst
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        transferAssetAndSetAccountDetail,
+		},
+		native.Function{
+			Comment: `
+				* @notice Starts a new batch of queued commands and returns a handle for
+				* it; pass that handle to queueTransferAsset/queueAddAssetQuantity/
+				* queueSubtractAssetQuantity/queueSetAccountDetail to append commands to
+				* it without submitting them, then to commitBatch to submit everything
+				* queued as one iroha.Tx, or to abortBatch to discard it unsubmitted.
+				* See iroha.BeginTx's doc comment for how this bridge stands in for
+				* per-call-frame handle isolation without a tx-scoped identifier to key
+				* the batch registry on.
+				* @return Handle identifying the new batch
+				`,
+			PermFlag: permission.Call,
+			F:        beginBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Queues a TransferAsset command on an open batch (see
+				* beginBatch) instead of submitting it immediately.
+				* @param Handle batch handle returned by beginBatch
+				* @param Src source account address
+				* @param Dst destination account address
+				* @param Description description of the transfer
+				* @param Asset asset ID
+				* @param Amount amount to transfer
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        queueTransferAsset,
+		},
+		native.Function{
+			Comment: `
+				* @notice Queues an AddAssetQuantity command on an open batch (see
+				* beginBatch) instead of submitting it immediately.
+				* @param Handle batch handle returned by beginBatch
+				* @param Asset asset ID
+				* @param Amount amount to add
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: IrohaModifyAssetSupply,
+			F:        queueAddAssetQuantity,
+		},
+		native.Function{
+			Comment: `
+				* @notice Queues a SubtractAssetQuantity command on an open batch (see
+				* beginBatch) instead of submitting it immediately.
+				* @param Handle batch handle returned by beginBatch
+				* @param Asset asset ID
+				* @param Amount amount to subtract
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: IrohaModifyAssetSupply,
+			F:        queueSubtractAssetQuantity,
+		},
+		native.Function{
+			Comment: `
+				* @notice Queues a SetAccountDetail command on an open batch (see
+				* beginBatch) instead of submitting it immediately.
+				* @param Handle batch handle returned by beginBatch
+				* @param Account account to set the detail on
+				* @param Key detail key
+				* @param Value detail value
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        queueSetAccountDetail,
+		},
+		native.Function{
+			Comment: `
+				* @notice Submits every command queued on handle (see beginBatch) as a
+				* single iroha.Tx and closes the batch. Returns an error - reverting
+				* this whole EVM call via the same path an unbatched command's failure
+				* already would - if any queued command fails; commands already
+				* submitted before the failing one stay committed on the Iroha side
+				* regardless (see iroha.Tx.Commit's own doc comment), even though the
+				* EVM-side effects of this call are rolled back.
+				* @param Handle batch handle returned by beginBatch
+				* @return 'true' if every queued command succeeded
+				`,
+			PermFlag: permission.Call,
+			F:        commitBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Discards every command queued on handle (see beginBatch)
+				* without submitting any of them, and closes the batch.
+				* @param Handle batch handle returned by beginBatch
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        abortBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Builds an unsigned iroha transaction out of commandsJSON
+				* without submitting it, for an MST wallet to collect co-signer
+				* signatures against off-chain before calling submitSignedTx
+				* @param CreatorAccountId account the transaction will be created by
+				* @param Quorum minimum signature count iroha requires before it will
+				* accept the transaction
+				* @param CommandsJSON JSON array of {kind, ...fields} commands - kind
+				* is one of "transferAsset", "addAssetQuantity",
+				* "subtractAssetQuantity", "setAccountDetail" (see
+				* iroha.UnsignedTxCommand)
+				* @return TxHash the hash co-signers must sign, and Payload the
+				* marshalled unsigned transaction to submit via submitSignedTx
+				`,
+			PermFlag: permission.Call,
+			F:        buildUnsignedTx,
+		},
+		native.Function{
+			Comment: `
+				* @notice Relays a signed transaction built by buildUnsignedTx, plus
+				* its collected co-signer signatures, for iroha to finish MST
+				* quorum-collection and commit. Not implemented in this bridge - see
+				* iroha.SubmitSignedTx's doc comment for why - and always reverts.
+				* @param Payload the unsigned transaction bytes buildUnsignedTx returned
+				* @param SignaturesRLP RLP-encoded signature bundle collected off-chain
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        submitSignedTx,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets a transaction's status (STATELESS_VALID=0,
+				* STATEFUL_VALID=1, MST_PENDING=2, MST_EXPIRED=3, COMMITTED=4,
+				* REJECTED=5), mirroring iroha's own TxStatus. Not implemented in this
+				* bridge - see iroha.GetTxStatus's doc comment for why - and always
+				* reverts. A real implementation would emit
+				* IrohaTxStateChanged(bytes32 txHash, uint8 newState) from whatever
+				* polls or subscribes to status changes, the same way every other
+				* mutating function here emits its own event (see native_events.go) -
+				* there is nothing in this bridge that can detect such a change yet.
+				* @param TxHash hash returned by buildUnsignedTx
+				* @return State the transaction's current status
+				`,
+			PermFlag: permission.Call,
+			F:        getTxStatus,
+		},
 	)
 )
 
@@ -356,7 +613,7 @@ type transferAssetRets struct {
 func transferAsset(ctx native.Context, args transferAssetArgs) (transferAssetRets, error) {
 	err := iroha.TransferAsset(args.Src, args.Dst, args.Asset, args.Desc, args.Amount)
 	if err != nil {
-		return transferAssetRets{Result: false}, err
+		return transferAssetRets{Result: false}, revertError(err)
 	}
 
 	ctx.Logger.Trace.Log("function", "transferAsset",
@@ -366,9 +623,269 @@ func transferAsset(ctx native.Context, args transferAssetArgs) (transferAssetRet
 		"description", args.Desc,
 		"amount", args.Amount)
 
+	emitIrohaEvent(ctx, "IrohaTransfer(string,string,string,string,string)",
+		args.Src, args.Dst, args.Asset, args.Amount, args.Desc)
+
 	return transferAssetRets{Result: true}, nil
 }
 
+type transferAssetPathArgs struct {
+	Src       string
+	Dst       string
+	SendAsset string
+	SendMax   string
+	DestAsset string
+	DestMin   string
+	Path      string
+}
+
+type transferAssetPathRets struct {
+	Result bool
+}
+
+func transferAssetPath(ctx native.Context, args transferAssetPathArgs) (transferAssetPathRets, error) {
+	var path []string
+	if args.Path != "" {
+		if err := json.Unmarshal([]byte(args.Path), &path); err != nil {
+			return transferAssetPathRets{Result: false}, fmt.Errorf("transferAssetPath: invalid path %q: %s", args.Path, err.Error())
+		}
+	}
+
+	err := iroha.TransferAssetPath(args.Src, args.Dst, args.SendAsset, args.SendMax, args.DestAsset, args.DestMin, path)
+	if err != nil {
+		return transferAssetPathRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "transferAssetPath",
+		"src", args.Src,
+		"dst", args.Dst,
+		"sendAsset", args.SendAsset,
+		"sendMax", args.SendMax,
+		"destAsset", args.DestAsset,
+		"destMin", args.DestMin,
+		"path", args.Path)
+
+	emitIrohaEvent(ctx, "IrohaTransferPath(string,string,string,string,string,string,string)",
+		args.Src, args.Dst, args.SendAsset, args.SendMax, args.DestAsset, args.DestMin, args.Path)
+
+	return transferAssetPathRets{Result: true}, nil
+}
+
+type transferAssetAndSetAccountDetailArgs struct {
+	Src    string
+	Dst    string
+	Asset  string
+	Desc   string
+	Amount string
+	Key    string
+	Value  string
+}
+
+type transferAssetAndSetAccountDetailRets struct {
+	Result bool
+}
+
+func transferAssetAndSetAccountDetail(ctx native.Context, args transferAssetAndSetAccountDetailArgs) (transferAssetAndSetAccountDetailRets, error) {
+	_, err := iroha.NewTx().
+		Transfer(args.Src, args.Dst, args.Asset, args.Desc, args.Amount).
+		SetAccountDetail(args.Dst, args.Key, args.Value).
+		Commit()
+	if err != nil {
+		return transferAssetAndSetAccountDetailRets{Result: false}, revertError(err)
+	}
+
+	ctx.Logger.Trace.Log("function", "transferAssetAndSetAccountDetail",
+		"src", args.Src,
+		"dst", args.Dst,
+		"assetID", args.Asset,
+		"description", args.Desc,
+		"amount", args.Amount,
+		"key", args.Key,
+		"value", args.Value)
+
+	emitIrohaEvent(ctx, "IrohaTransferAndAccountDetailSet(string,string,string,string,string,string,string)",
+		args.Src, args.Dst, args.Asset, args.Amount, args.Desc, args.Key, args.Value)
+
+	return transferAssetAndSetAccountDetailRets{Result: true}, nil
+}
+
+type beginBatchArgs struct {
+}
+
+type beginBatchRets struct {
+	Handle string
+}
+
+func beginBatch(ctx native.Context, args beginBatchArgs) (beginBatchRets, error) {
+	handle := iroha.BeginTx()
+
+	ctx.Logger.Trace.Log("function", "beginBatch", "handle", handle)
+
+	return beginBatchRets{Handle: strconv.FormatUint(handle, 10)}, nil
+}
+
+func parseBatchHandle(handle string) (uint64, error) {
+	h, err := strconv.ParseUint(handle, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid batch handle %q: %s", handle, err.Error())
+	}
+	return h, nil
+}
+
+type queueTransferAssetArgs struct {
+	Handle string
+	Src    string
+	Dst    string
+	Asset  string
+	Desc   string
+	Amount string
+}
+
+type queueTransferAssetRets struct {
+	Result bool
+}
+
+func queueTransferAsset(ctx native.Context, args queueTransferAssetArgs) (queueTransferAssetRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return queueTransferAssetRets{Result: false}, err
+	}
+	if err := iroha.QueueTransfer(handle, args.Src, args.Dst, args.Asset, args.Desc, args.Amount); err != nil {
+		return queueTransferAssetRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "queueTransferAsset",
+		"handle", args.Handle,
+		"src", args.Src,
+		"dst", args.Dst,
+		"assetID", args.Asset,
+		"description", args.Desc,
+		"amount", args.Amount)
+
+	return queueTransferAssetRets{Result: true}, nil
+}
+
+type queueAddAssetQuantityArgs struct {
+	Handle string
+	Asset  string
+	Amount string
+}
+
+type queueAddAssetQuantityRets struct {
+	Result bool
+}
+
+func queueAddAssetQuantity(ctx native.Context, args queueAddAssetQuantityArgs) (queueAddAssetQuantityRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return queueAddAssetQuantityRets{Result: false}, err
+	}
+	if err := iroha.QueueAddAssetQuantity(handle, args.Asset, args.Amount); err != nil {
+		return queueAddAssetQuantityRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "queueAddAssetQuantity",
+		"handle", args.Handle,
+		"asset", args.Asset,
+		"amount", args.Amount)
+
+	return queueAddAssetQuantityRets{Result: true}, nil
+}
+
+type queueSubtractAssetQuantityArgs struct {
+	Handle string
+	Asset  string
+	Amount string
+}
+
+type queueSubtractAssetQuantityRets struct {
+	Result bool
+}
+
+func queueSubtractAssetQuantity(ctx native.Context, args queueSubtractAssetQuantityArgs) (queueSubtractAssetQuantityRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return queueSubtractAssetQuantityRets{Result: false}, err
+	}
+	if err := iroha.QueueSubtractAssetQuantity(handle, args.Asset, args.Amount); err != nil {
+		return queueSubtractAssetQuantityRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "queueSubtractAssetQuantity",
+		"handle", args.Handle,
+		"asset", args.Asset,
+		"amount", args.Amount)
+
+	return queueSubtractAssetQuantityRets{Result: true}, nil
+}
+
+type queueSetAccountDetailArgs struct {
+	Handle  string
+	Account string
+	Key     string
+	Value   string
+}
+
+type queueSetAccountDetailRets struct {
+	Result bool
+}
+
+func queueSetAccountDetail(ctx native.Context, args queueSetAccountDetailArgs) (queueSetAccountDetailRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return queueSetAccountDetailRets{Result: false}, err
+	}
+	if err := iroha.QueueSetAccountDetail(handle, args.Account, args.Key, args.Value); err != nil {
+		return queueSetAccountDetailRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "queueSetAccountDetail",
+		"handle", args.Handle,
+		"account", args.Account,
+		"key", args.Key,
+		"value", args.Value)
+
+	return queueSetAccountDetailRets{Result: true}, nil
+}
+
+type batchHandleArgs struct {
+	Handle string
+}
+
+type batchHandleRets struct {
+	Result bool
+}
+
+func commitBatch(ctx native.Context, args batchHandleArgs) (batchHandleRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return batchHandleRets{Result: false}, err
+	}
+	if _, err := iroha.CommitBatch(handle); err != nil {
+		return batchHandleRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "commitBatch", "handle", args.Handle)
+
+	emitIrohaEvent(ctx, "IrohaBatchCommitted(string)", args.Handle)
+
+	return batchHandleRets{Result: true}, nil
+}
+
+func abortBatch(ctx native.Context, args batchHandleArgs) (batchHandleRets, error) {
+	handle, err := parseBatchHandle(args.Handle)
+	if err != nil {
+		return batchHandleRets{Result: false}, err
+	}
+	if err := iroha.AbortBatch(handle); err != nil {
+		return batchHandleRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "abortBatch", "handle", args.Handle)
+
+	return batchHandleRets{Result: true}, nil
+}
+
 type createAccountArgs struct {
 	Name   string
 	Domain string
@@ -382,7 +899,7 @@ type createAccountRets struct {
 func createAccount(ctx native.Context, args createAccountArgs) (createAccountRets, error) {
 	err := iroha.CreateAccount(args.Name, args.Domain, args.Key)
 	if err != nil {
-		return createAccountRets{Result: false}, err
+		return createAccountRets{Result: false}, revertError(err)
 	}
 
 	ctx.Logger.Trace.Log("function", "createAccount",
@@ -390,6 +907,8 @@ func createAccount(ctx native.Context, args createAccountArgs) (createAccountRet
 		"domain", args.Domain,
 		"key", args.Key)
 
+	emitIrohaEvent(ctx, "IrohaAccountCreated(string,string,string)", args.Name, args.Domain, args.Key)
+
 	return createAccountRets{Result: true}, nil
 }
 
@@ -405,13 +924,15 @@ type addAssetQuantityRets struct {
 func addAssetQuantity(ctx native.Context, args addAssetQuantityArgs) (addAssetQuantityRets, error) {
 	err := iroha.AddAssetQuantity(args.Asset, args.Amount)
 	if err != nil {
-		return addAssetQuantityRets{Result: false}, err
+		return addAssetQuantityRets{Result: false}, revertError(err)
 	}
 
 	ctx.Logger.Trace.Log("function", "addAssetQuantity",
 		"asset", args.Asset,
 		"amount", args.Amount)
 
+	emitIrohaEvent(ctx, "IrohaAssetQuantityAdded(string,string)", args.Asset, args.Amount)
+
 	return addAssetQuantityRets{Result: true}, nil
 }
 
@@ -427,13 +948,15 @@ type subtractAssetQuantityRets struct {
 func subtractAssetQuantity(ctx native.Context, args subtractAssetQuantityArgs) (subtractAssetQuantityRets, error) {
 	err := iroha.SubtractAssetQuantity(args.Asset, args.Amount)
 	if err != nil {
-		return subtractAssetQuantityRets{Result: false}, err
+		return subtractAssetQuantityRets{Result: false}, revertError(err)
 	}
 
 	ctx.Logger.Trace.Log("function", "subtractAssetQuantity",
 		"asset", args.Asset,
 		"amount", args.Amount)
 
+	emitIrohaEvent(ctx, "IrohaAssetQuantitySubtracted(string,string)", args.Asset, args.Amount)
+
 	return subtractAssetQuantityRets{Result: true}, nil
 }
 
@@ -450,7 +973,7 @@ type setAccountDetailRets struct {
 func setAccountDetail(ctx native.Context, args setAccountDetailArgs) (setAccountDetailRets, error) {
 	err := iroha.SetAccountDetail(args.Account, args.Key, args.Value)
 	if err != nil {
-		return setAccountDetailRets{Result: false}, err
+		return setAccountDetailRets{Result: false}, revertError(err)
 	}
 
 	ctx.Logger.Trace.Log("function", "setAccountDetail",
@@ -458,6 +981,8 @@ func setAccountDetail(ctx native.Context, args setAccountDetailArgs) (setAccount
 		"key", args.Key,
 		"value", args.Value)
 
+	emitIrohaEvent(ctx, "IrohaAccountDetailSet(string,string,string)", args.Account, args.Key, args.Value)
+
 	return setAccountDetailRets{Result: true}, nil
 }
 
@@ -498,6 +1023,8 @@ func setAccountQuorum(ctx native.Context, args setAccountQuorumArgs) (setAccount
 		"account", args.Account,
 		"quorum", args.Quorum)
 
+	emitIrohaEvent(ctx, "IrohaAccountQuorumSet(string,string)", args.Account, args.Quorum)
+
 	return setAccountQuorumRets{Result: true}, nil
 }
 
@@ -520,6 +1047,8 @@ func addSignatory(ctx native.Context, args addSignatoryArgs) (addSignatoryRets,
 		"account id", args.Account,
 		"public key", args.Key)
 
+	emitIrohaEvent(ctx, "IrohaSignatoryAdded(string,string)", args.Account, args.Key)
+
 	return addSignatoryRets{Result: true}, nil
 }
 
@@ -542,6 +1071,8 @@ func removeSignatory(ctx native.Context, args removeSignatoryArgs) (removeSignat
 		"account id", args.Account,
 		"public key", args.Key)
 
+	emitIrohaEvent(ctx, "IrohaSignatoryRemoved(string,string)", args.Account, args.Key)
+
 	return removeSignatoryRets{Result: true}, nil
 }
 
@@ -564,6 +1095,8 @@ func createDomain(ctx native.Context, args createDomainArgs) (createDomainRets,
 		"domain name", args.Domain,
 		"default role", args.Role)
 
+	emitIrohaEvent(ctx, "IrohaDomainCreated(string,string)", args.Domain, args.Role)
+
 	return createDomainRets{Result: true}, nil
 }
 
@@ -609,6 +1142,8 @@ func createAsset(ctx native.Context, args createAssetArgs) (createAssetRets, err
 		"domain id", args.Domain,
 		"precision", args.Precision)
 
+	emitIrohaEvent(ctx, "IrohaAssetCreated(string,string,string)", args.Name, args.Domain, args.Precision)
+
 	return createAssetRets{Result: true}, nil
 }
 
@@ -673,6 +1208,8 @@ func appendRole(ctx native.Context, args appendRoleArgs) (appendRoleRets, error)
 		"account name", args.Account,
 		"new role", args.Role)
 
+	emitIrohaEvent(ctx, "IrohaRoleAppended(string,string)", args.Account, args.Role)
+
 	return appendRoleRets{Result: true}, nil
 }
 
@@ -695,6 +1232,8 @@ func detachRole(ctx native.Context, args detachRoleArgs) (detachRoleRets, error)
 		"account name", args.Account,
 		"removed role", args.Role)
 
+	emitIrohaEvent(ctx, "IrohaRoleDetached(string,string)", args.Account, args.Role)
+
 	return detachRoleRets{Result: true}, nil
 }
 
@@ -717,6 +1256,8 @@ func addPeer(ctx native.Context, args addPeerArgs) (addPeerRets, error) {
 		"peer address", args.Address,
 		"peer key", args.PeerKey)
 
+	emitIrohaEvent(ctx, "IrohaPeerAdded(string,string)", args.Address, args.PeerKey)
+
 	return addPeerRets{Result: true}, nil
 }
 
@@ -737,6 +1278,8 @@ func removePeer(ctx native.Context, args removePeerArgs) (removePeerRets, error)
 	ctx.Logger.Trace.Log("function", "removePeer",
 		"peer key", args.PeerKey)
 
+	emitIrohaEvent(ctx, "IrohaPeerRemoved(string)", args.PeerKey)
+
 	return removePeerRets{Result: true}, nil
 }
 
@@ -758,6 +1301,8 @@ func grantPermission(ctx native.Context, args GrantPermissionArgs) (GrantPermiss
 	ctx.Logger.Trace.Log("function", "GrantPermission",
 		"account", args.AccountId, "Permission", args.Permission)
 
+	emitIrohaEvent(ctx, "IrohaPermissionGranted(string,string)", args.AccountId, args.Permission)
+
 	return GrantPermissionRets{Result: true}, nil
 }
 
@@ -773,6 +1318,8 @@ func revokePermission(ctx native.Context, args RevokePermissionArgs) (RevokePerm
 	ctx.Logger.Trace.Log("function", "RevokePermission",
 		"account", args.AccountId, "Permission", args.Permission)
 
+	emitIrohaEvent(ctx, "IrohaPermissionRevoked(string,string)", args.AccountId, args.Permission)
+
 	return RevokePermissionRets{Result: true}, nil
 }
 
@@ -798,6 +1345,8 @@ func compareAndSetAccountDetail(ctx native.Context, args compareAndSetAccountDet
 		"account", args.AccountId, "key", args.Key, "value", args.Value,
 		"old value", args.OldValue, "check empty", args.CheckEmpty)
 
+	emitIrohaEvent(ctx, "IrohaAccountDetailCompareAndSet(string,string,string)", args.AccountId, args.Key, args.Value)
+
 	return compareAndSetAccountDetailRets{Result: true}, nil
 }
 
@@ -819,6 +1368,8 @@ func createRole(ctx native.Context, args createRoleArgs) (createRoleRets, error)
 	ctx.Logger.Trace.Log("function", "CreateRole",
 		"Role Name", args.RoleName, "Permissions", args.Permissions)
 
+	emitIrohaEvent(ctx, "IrohaRoleCreated(string,string)", args.RoleName, args.Permissions)
+
 	return createRoleRets{Result: true}, nil
 }
 
@@ -905,20 +1456,107 @@ type GetAccountTransactionsArgs struct {
 }
 
 type getAccountTransactionsRets struct {
-	Result string
+	Result     string
+	TotalCount string
+	Cursor     string
 }
 
 func getAccountTransactions(ctx native.Context, args GetAccountTransactionsArgs) (getAccountTransactionsRets, error) {
-	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.PageSize, Ordering: &args.Ordering,
+	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.FirstTxHash, Ordering: &args.Ordering,
 		FirstTxTime: &args.FirstTxTime, LastTxTime: &args.LastTxTime, FirstTxHeight: &args.FirstTxHeight, LastTxHeight: &args.LastTxHeight}
-	transactions, err := iroha.GetAccountTransactions(args.Account, &paginationMetaArg)
+	page, err := iroha.GetAccountTransactionsPage(args.Account, &paginationMetaArg)
 	if err != nil {
 		return getAccountTransactionsRets{}, err
 	}
 	ctx.Logger.Trace.Log("function", "GetAccountTransactions",
 		"account", args.Account)
-	result, err := json.Marshal(transactions)
-	return getAccountTransactionsRets{Result: string(result)}, nil
+
+	return accountTransactionsPageRets(ctx, args.Account, args.PageSize, args.Ordering, page)
+}
+
+// accountTransactionsPageRets turns page into the getAccountTransactionsRets
+// shape shared by getAccountTransactions and continueAccountTransactions: it
+// JSON-marshals the page's transactions, and - if iroha reports a next page
+// - stores account/pageSize/ordering plus a cursor pointing at page's last
+// transaction under a fresh handle so continueAccountTransactions can
+// resume the scan from just that handle.
+func accountTransactionsPageRets(ctx native.Context, account, pageSize, ordering string, page iroha.TransactionsPage) (getAccountTransactionsRets, error) {
+	result, err := json.Marshal(page.Transactions)
+	if err != nil {
+		return getAccountTransactionsRets{}, err
+	}
+
+	rets := getAccountTransactionsRets{
+		Result:     string(result),
+		TotalCount: strconv.FormatUint(uint64(page.TotalCount), 10),
+	}
+	if !page.HasNext {
+		return rets, nil
+	}
+
+	irohaCursor := iroha_model.MakeCursor(ordering, page.NextTxHeight, 0, page.NextTxHash)
+	handle, err := newQueryCursorHandle()
+	if err != nil {
+		return getAccountTransactionsRets{}, err
+	}
+	storeAccountTransactionsCursor(ctx.Caller, handle, storedAccountTransactionsQuery{
+		account:     account,
+		pageSize:    pageSize,
+		ordering:    ordering,
+		irohaCursor: irohaCursor,
+	})
+	rets.Cursor = handle
+	return rets, nil
+}
+
+type continueAccountTransactionsArgs struct {
+	Cursor string
+}
+
+func continueAccountTransactions(ctx native.Context, args continueAccountTransactionsArgs) (getAccountTransactionsRets, error) {
+	query, err := takeAccountTransactionsCursor(ctx.Caller, args.Cursor)
+	if err != nil {
+		return getAccountTransactionsRets{}, revertError(err)
+	}
+
+	cursor := query.irohaCursor
+	paginationMetaArg := iroha_model.TxPaginationMeta{
+		PageSize: &query.pageSize,
+		Ordering: &query.ordering,
+		Cursor:   &cursor,
+	}
+	page, err := iroha.GetAccountTransactionsPage(query.account, &paginationMetaArg)
+	if err != nil {
+		return getAccountTransactionsRets{}, err
+	}
+	ctx.Logger.Trace.Log("function", "continueAccountTransactions",
+		"account", query.account)
+
+	return accountTransactionsPageRets(ctx, query.account, query.pageSize, query.ordering, page)
+}
+
+type getAccountTransactionsCountArgs struct {
+	Account string
+}
+
+type getAccountTransactionsCountRets struct {
+	Result string
+}
+
+// getAccountTransactionsCount fetches only a single transaction's worth of
+// page (the smallest PageSize iroha's own validation accepts) purely to
+// read back TotalCount, so a UI can size a progress bar before paging
+// through getAccountTransactions/continueAccountTransactions at all.
+func getAccountTransactionsCount(ctx native.Context, args getAccountTransactionsCountArgs) (getAccountTransactionsCountRets, error) {
+	pageSize, ordering := "1", ""
+	paginationMetaArg := iroha_model.TxPaginationMeta{PageSize: &pageSize, Ordering: &ordering}
+	page, err := iroha.GetAccountTransactionsPage(args.Account, &paginationMetaArg)
+	if err != nil {
+		return getAccountTransactionsCountRets{}, err
+	}
+	ctx.Logger.Trace.Log("function", "getAccountTransactionsCount",
+		"account", args.Account)
+	return getAccountTransactionsCountRets{Result: strconv.FormatUint(uint64(page.TotalCount), 10)}, nil
 }
 
 type GetPendingTransactionsArgs struct {
@@ -934,7 +1572,7 @@ type getPendingTransactionsRets struct {
 }
 
 func getPendingTransactions(ctx native.Context, args GetPendingTransactionsArgs) (getPendingTransactionsRets, error) {
-	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.PageSize, Ordering: &args.Ordering,
+	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.FirstTxHash, Ordering: &args.Ordering,
 		FirstTxTime: &args.FirstTxTime, LastTxTime: &args.LastTxTime}
 	transactions, err := iroha.GetPendingTransactions(&paginationMetaArg)
 	if err != nil {
@@ -962,7 +1600,7 @@ type getAccountAssetTransactionsRets struct {
 }
 
 func getAccountAssetTransactions(ctx native.Context, args GetAccountAssetTransactionsArgs) (getAccountAssetTransactionsRets, error) {
-	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.PageSize, Ordering: &args.Ordering,
+	paginationMetaArg := iroha_model.TxPaginationMeta{ PageSize: &args.PageSize, FirstTxHash: &args.FirstTxHash, Ordering: &args.Ordering,
 		FirstTxTime: &args.FirstTxTime, LastTxTime: &args.LastTxTime, FirstTxHeight: &args.FirstTxHeight, LastTxHeight: &args.LastTxHeight}
 	transactions, err := iroha.GetAccountAssetTransactions(args.AccountId, args.AssetId, &paginationMetaArg)
 	if err != nil {
@@ -1000,13 +1638,47 @@ func MustCreateNatives() *native.Natives {
 }
 
 func createNatives() (*native.Natives, error) {
-	ns, err := native.Merge(ServiceContract, native.Permissions, native.Precompiles)
+	ns, err := native.Merge(ServiceContract, ServiceContractV2, native.Permissions, native.Precompiles)
 	if err != nil {
 		return nil, err
 	}
+
+	// Registering every module this bridge itself owns here, in one place,
+	// is what lets IsNative below (and the delegation check in main.go)
+	// consult a map instead of each knowing every reserved address by name.
+	// A future module (a separate permissions contract, a pagination helper
+	// contract, and so on - see native_registry.go's doc comment) only needs
+	// its own RegisterNative call added here, not a new branch in IsNative.
+	if _, err := RegisterNative("ServiceContract", ServiceContractAddress, ServiceContract); err != nil {
+		return nil, err
+	}
+	if _, err := RegisterNative("ServiceContractV2", ServiceContractV2Address, ServiceContractV2); err != nil {
+		return nil, err
+	}
+
 	return ns, nil
 }
 
+// IsNative reports whether acc is one of this bridge's own reserved native
+// contract addresses (see native_registry.go), the ones createNatives just
+// registered above. It no longer lower-cases and string-compares acc
+// against a hardcoded address list on every call: crypto.Address is a fixed
+// byte array, so once acc parses, the registry lookup is a single map read.
 func IsNative(acc string) bool {
-	return strings.ToLower(acc) == "a6abc17819738299b3b2c1ce46d55c74f04e290c"
+	addr, err := crypto.AddressFromHexString(acc)
+	if err != nil {
+		return false
+	}
+	return isRegisteredNative(addr)
+}
+
+// revertError renders an iroha.CommandError/QueryError as an
+// "IROHA:<code>:<name>" revert reason so a Solidity caller, which only ever
+// sees the returned error's message as its revert string, can still branch
+// on the underlying Iroha error code. Other errors pass through unchanged.
+func revertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s", iroha.RevertReason(err))
 }
@@ -0,0 +1,134 @@
+package evm
+
+import (
+	"encoding/json"
+
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/execution/native"
+	pb "iroha.protocol"
+)
+
+// transactionsBatchChunkSize is how many hashes GetTransactionsBatch asks
+// iroha for in a single underlying GetTransactions query - "respecting peer
+// query size limits" the way assetTransactionsScanPageSize (see
+// native_contract_query.go) respects them for account-asset scans. This
+// checkout has no vendored ametsuchi/torii query-size configuration to read
+// the peer's real limit from, so this is a conservative constant rather than
+// a value derived from the peer at runtime.
+const transactionsBatchChunkSize = 50
+
+// GetTransactionsBatchArgs is GetTransactionsArgs with Hashes as a proper
+// string array instead of a comma/JSON-joined string, plus the two knobs
+// this batch version needs: IncludeMissing trades extra per-hash retry
+// queries (see resolveTransactionsBatchChunk) for being able to report which
+// hashes in a failed chunk didn't resolve, and MaxBytes caps how much
+// marshalled transaction data the response accumulates before it stops
+// early and reports Truncated.
+type GetTransactionsBatchArgs struct {
+	Hashes         []string
+	IncludeMissing bool
+	MaxBytes       uint32
+}
+
+type getTransactionsBatchRets struct {
+	Result string
+}
+
+// transactionsBatchResponse is GetTransactionsBatch's compact JSON result:
+// Found maps each resolved hash to its transaction, Missing lists hashes
+// that didn't resolve (a chunk that failed without IncludeMissing set lists
+// every one of its hashes there, with none in Found - see
+// resolveTransactionsBatchChunk), and Truncated is set once
+// MaxBytes stops the scan before every chunk was processed.
+type transactionsBatchResponse struct {
+	Found     map[string]json.RawMessage `json:"found"`
+	Missing   []string                   `json:"missing"`
+	Truncated bool                       `json:"truncated"`
+}
+
+func getTransactionsBatch(ctx native.Context, args GetTransactionsBatchArgs) (getTransactionsBatchRets, error) {
+	found := map[string]json.RawMessage{}
+	missing := []string{}
+	truncated := false
+	var bytesUsed uint64
+
+chunks:
+	for start := 0; start < len(args.Hashes); start += transactionsBatchChunkSize {
+		end := start + transactionsBatchChunkSize
+		if end > len(args.Hashes) {
+			end = len(args.Hashes)
+		}
+		chunkFound, chunkMissing, err := resolveTransactionsBatchChunk(args.Hashes[start:end], args.IncludeMissing)
+		if err != nil {
+			return getTransactionsBatchRets{}, revertError(err)
+		}
+		missing = append(missing, chunkMissing...)
+
+		for hash, tx := range chunkFound {
+			raw, err := json.Marshal(tx)
+			if err != nil {
+				return getTransactionsBatchRets{}, revertError(err)
+			}
+			if args.MaxBytes != 0 && bytesUsed+uint64(len(raw)) > uint64(args.MaxBytes) {
+				truncated = true
+				break chunks
+			}
+			bytesUsed += uint64(len(raw))
+			found[hash] = raw
+		}
+	}
+
+	ctx.Logger.Trace.Log("function", "GetTransactionsBatch",
+		"requested", len(args.Hashes), "found", len(found), "missing", len(missing))
+
+	result, err := json.Marshal(transactionsBatchResponse{Found: found, Missing: missing, Truncated: truncated})
+	if err != nil {
+		return getTransactionsBatchRets{}, revertError(err)
+	}
+	return getTransactionsBatchRets{Result: string(result)}, nil
+}
+
+// resolveTransactionsBatchChunk resolves hashes (already no larger than
+// transactionsBatchChunkSize) in one GetTransactions query when possible.
+//
+// Iroha's GetTransactions has no per-hash status (see
+// iroha.GetTransactionsForHashes's doc comment), so a single bad hash fails
+// the whole chunk. When that happens and includeMissing is true, each hash
+// in the chunk is retried one at a time to find out which ones actually
+// resolve - expensive (one query per hash instead of one for the whole
+// chunk) but the only way this bridge can isolate the failure. When
+// includeMissing is false, a failed chunk is reported as entirely
+// unresolved without the retry cost, on the assumption that a caller who
+// didn't ask for Missing detail would rather get nothing back quickly than
+// pay for per-hash recovery.
+func resolveTransactionsBatchChunk(hashes []string, includeMissing bool) (map[string]*pb.Transaction, []string, error) {
+	if len(hashes) == 0 {
+		return nil, nil, nil
+	}
+
+	transactions, err := iroha.GetTransactionsForHashes(hashes)
+	if err == nil && len(transactions) == len(hashes) {
+		found := make(map[string]*pb.Transaction, len(hashes))
+		for i, hash := range hashes {
+			found[hash] = transactions[i]
+		}
+		return found, nil, nil
+	}
+
+	if !includeMissing {
+		return nil, hashes, nil
+	}
+
+	found := map[string]*pb.Transaction{}
+	var missing []string
+	for _, hash := range hashes {
+		txs, err := iroha.GetTransactionsForHashes([]string{hash})
+		if err != nil || len(txs) != 1 {
+			missing = append(missing, hash)
+			continue
+		}
+		found[hash] = txs[0]
+	}
+	return found, missing, nil
+}
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"math/big"
+
+	"vmCaller/iroha"
+
+	bin "github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+// transferAssetUintArgs is transferAssetArgs with Amount as uint256 (via
+// *big.Int, the same convention assetQuantityV2Args uses - see
+// native_contract_v2.go) instead of a pre-scaled decimal string, so a
+// go-ethereum abigen client built against a standard ERC-20-shaped ABI can
+// call this without first having to render Iroha's fixed-precision decimal
+// encoding itself. Src/Desc are kept as explicit fields rather than
+// inferring Src from ctx.Caller, matching every other transfer-shaped
+// function in this package (transferAsset, transferAssetPath,
+// transferAssetAndSetAccountDetail all take Src explicitly too).
+type transferAssetUintArgs struct {
+	Src    string
+	Dst    string
+	Asset  string
+	Desc   string
+	Amount *big.Int
+}
+
+type transferAssetUintRets struct {
+	Result bool
+}
+
+// transferAssetUint scales Amount down from its raw uint256 integer form to
+// the decimal string Iroha's TransferAsset command expects, using Asset's
+// actual precision (via iroha.AssetPrecision/iroha.Amount, which also
+// rejects a precision mismatch - see iroha/amount.go), then emits both the
+// IrohaTransfer log transferAsset already emits and a
+// Transfer(address,address,uint256) log carrying the unscaled integer
+// amount, so an off-the-shelf ERC-20 indexer watching for Transfer picks
+// this up without knowing anything about Iroha.
+func transferAssetUint(ctx native.Context, args transferAssetUintArgs) (transferAssetUintRets, error) {
+	precision, err := iroha.AssetPrecision(args.Asset)
+	if err != nil {
+		return transferAssetUintRets{Result: false}, revertError(err)
+	}
+	amount := iroha.Amount{Mantissa: args.Amount, Precision: precision}
+
+	if err := iroha.TransferAsset(args.Src, args.Dst, args.Asset, args.Desc, amount); err != nil {
+		return transferAssetUintRets{Result: false}, revertError(err)
+	}
+
+	ctx.Logger.Trace.Log("function", "transferAssetUint",
+		"src", args.Src,
+		"dst", args.Dst,
+		"assetID", args.Asset,
+		"description", args.Desc,
+		"amount", amount.String())
+
+	emitIrohaEvent(ctx, "IrohaTransfer(string,string,string,string,string)",
+		args.Src, args.Dst, args.Asset, amount.String(), args.Desc)
+	emitTransferUint256(ctx, args.Src, args.Dst, args.Amount)
+
+	return transferAssetUintRets{Result: true}, nil
+}
+
+// emitTransferUint256 emits Transfer(address,address,uint256) with amount
+// ABI-encoded as a single static uint256 word, the same layout go-ethereum's
+// abigen expects from a standard ERC-20 Transfer event. from/to are Iroha
+// account ids (e.g. "alice@domain"), mapped to the indexed from/to topics
+// with native.AddressFromName, the same deterministic Iroha-account-id-to-
+// EVM-address mapping main.go/static_call.go/delegation.go/create2.go/
+// execution/execution.go/trace_call.go already use - without it, every
+// Transfer would carry the zero address in both topics, which defeats the
+// point of emitting this log for an off-the-shelf ERC-20 indexer in the
+// first place, since such indexers primarily filter on indexed from/to.
+func emitTransferUint256(ctx native.Context, from, to string, amount *big.Int) {
+	data := make([]byte, 32)
+	amountBytes := amount.Bytes()
+	copy(data[32-len(amountBytes):], amountBytes)
+	topics := []bin.Word256{
+		eventTopic("Transfer(address,address,uint256)"),
+		bin.LeftPadWord256(native.AddressFromName(from).Bytes()),
+		bin.LeftPadWord256(native.AddressFromName(to).Bytes()),
+	}
+	emitLog(ctx, "transferAssetUint", topics, data)
+}
@@ -0,0 +1,55 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	bin "github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmthrgd/go-hex"
+)
+
+func TestEmitTransferUint256(t *testing.T) {
+	store := eventWriterMock{data: map[int][]byte{}}
+	ctx := native.Context{EventSink: NewIrohaEventSink(&store)}
+
+	n, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+
+	emitTransferUint256(ctx, "alice@domain", "bob@domain", n)
+	require.Len(t, store.data, 1)
+	logged := store.data[0]
+
+	wantFrom := bin.LeftPadWord256(native.AddressFromName("alice@domain").Bytes())
+	wantTo := bin.LeftPadWord256(native.AddressFromName("bob@domain").Bytes())
+	require.NotEqual(t, bin.Word256{}, wantFrom, "alice@domain must not map to the zero address")
+	require.NotEqual(t, wantFrom, wantTo, "distinct accounts must not collide on the same address")
+
+	amountBytes := n.Bytes()
+	wantData := make([]byte, 32)
+	copy(wantData[32-len(amountBytes):], amountBytes)
+
+	want := append([]byte{}, ServiceContractAddress.Bytes()...)
+	want = append(want, eventTopic("Transfer(address,address,uint256)").Bytes()...)
+	want = append(want, wantFrom.Bytes()...)
+	want = append(want, wantTo.Bytes()...)
+	want = append(want, wantData...)
+
+	assert.Equal(t, want, logged)
+}
+
+func TestEmitTransferUint256DataEncoding(t *testing.T) {
+	// amount is left-padded to a 32-byte static uint256 word, the same shape
+	// a standard ERC-20 Transfer(address,address,uint256) log's data carries.
+	n, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+
+	data := make([]byte, 32)
+	amountBytes := n.Bytes()
+	copy(data[32-len(amountBytes):], amountBytes)
+
+	want := hex.MustDecodeString("00000000000000000000000000000000000000018ee90ff6c373e0ee4e3f0ad2")
+	assert.Equal(t, want, data)
+}
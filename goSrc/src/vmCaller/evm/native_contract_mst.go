@@ -0,0 +1,66 @@
+package evm
+
+import (
+	"vmCaller/iroha"
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+type buildUnsignedTxArgs struct {
+	CreatorAccountId string
+	Quorum           uint32
+	CommandsJSON     string
+}
+
+type buildUnsignedTxRets struct {
+	TxHash  [32]byte
+	Payload []byte
+}
+
+func buildUnsignedTx(ctx native.Context, args buildUnsignedTxArgs) (buildUnsignedTxRets, error) {
+	txHash, payload, err := iroha.BuildUnsignedTx(args.CreatorAccountId, args.Quorum, args.CommandsJSON)
+	if err != nil {
+		return buildUnsignedTxRets{}, revertError(err)
+	}
+
+	ctx.Logger.Trace.Log("function", "buildUnsignedTx",
+		"creator", args.CreatorAccountId,
+		"quorum", args.Quorum)
+
+	return buildUnsignedTxRets{TxHash: txHash, Payload: payload}, nil
+}
+
+type submitSignedTxArgs struct {
+	Payload       []byte
+	SignaturesRLP []byte
+}
+
+type submitSignedTxRets struct {
+	Result bool
+}
+
+// submitSignedTx always reverts - see iroha.SubmitSignedTx's doc comment
+// for why this bridge has no way to relay a signed transaction to iroha.
+func submitSignedTx(ctx native.Context, args submitSignedTxArgs) (submitSignedTxRets, error) {
+	if err := iroha.SubmitSignedTx(args.Payload, args.SignaturesRLP); err != nil {
+		return submitSignedTxRets{Result: false}, revertError(err)
+	}
+	return submitSignedTxRets{Result: true}, nil
+}
+
+type getTxStatusArgs struct {
+	TxHash [32]byte
+}
+
+type getTxStatusRets struct {
+	State uint8
+}
+
+// getTxStatus always reverts - see iroha.GetTxStatus's doc comment for why
+// this bridge has no way to query a transaction's status from iroha.
+func getTxStatus(ctx native.Context, args getTxStatusArgs) (getTxStatusRets, error) {
+	state, err := iroha.GetTxStatus(args.TxHash)
+	if err != nil {
+		return getTxStatusRets{}, revertError(err)
+	}
+	return getTxStatusRets{State: uint8(state)}, nil
+}
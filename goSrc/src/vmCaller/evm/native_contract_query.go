@@ -0,0 +1,235 @@
+package evm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"vmCaller/iroha"
+	"vmCaller/iroha_model"
+
+	"github.com/hyperledger/burrow/execution/native"
+	pb "iroha.protocol"
+)
+
+// assetTransactionsScanPageSize is how many transactions
+// queryAccountAssetTransactions asks iroha's own cursor API for per scan,
+// independent of the caller's requested PageSize - the two serve different
+// purposes: this one amortises the number of round trips to iroha while
+// scanning for the requested offset window, that one is the window size
+// itself.
+const assetTransactionsScanPageSize = "100"
+
+// maxAssetTransactionsPagesScanned caps how many iroha pages
+// queryAccountAssetTransactions will walk looking for Page's window before
+// giving up and returning whatever it has found so far - the "configurable
+// cap on pages scanned to protect the peer" this function's request asked
+// for. It is a const, not a caller-supplied argument, for the same reason
+// queryCursorTTL (see tx_query_cursor.go) isn't: a Solidity caller has no
+// legitimate reason to ask this bridge to scan further than its operator is
+// willing to let it.
+const maxAssetTransactionsPagesScanned = 50
+
+// QueryAccountAssetTransactionsArgs is a Wormhole-style offset/page request
+// layered on top of iroha's own cursor-based GetAccountAssetTransactions:
+// Page/PageSize pick a window the way a typical REST list endpoint would,
+// and Counterparty/CommandType/MinAmount/MaxAmount/Status filter the
+// matched rows before that window is cut. A zero MinAmount/MaxAmount means
+// "no bound" on that side - there is no legitimate reason to ask for
+// amounts capped at exactly zero - and an empty Counterparty/CommandType/
+// Status means "no filter" on that field.
+type QueryAccountAssetTransactionsArgs struct {
+	AccountId    string
+	AssetId      string
+	Page         uint32
+	PageSize     uint32
+	Counterparty string
+	CommandType  string
+	MinAmount    *big.Int
+	MaxAmount    *big.Int
+	Status       string
+}
+
+type queryAccountAssetTransactionsRets struct {
+	Result string
+}
+
+// accountAssetTransactionRow is one entry of the compact JSON response this
+// function returns, deliberately flat so the EVM contract decoding it never
+// has to parse a pb.Transaction itself.
+type accountAssetTransactionRow struct {
+	Hash      string `json:"hash"`
+	Timestamp uint64 `json:"timestamp"`
+	// Height is always 0: neither a pb.Transaction nor a
+	// GetAccountAssetTransactions response carries the height of the block
+	// that committed it (TransactionsPageResponse.NextTxInfo only carries
+	// the next page's cursor tx, not a per-row height) - this checkout has
+	// no vendored iroha.protocol source to check for a field that does, so
+	// rather than guess at one on every row, Height is left honestly unset.
+	Height      uint64 `json:"height"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+	AssetId     string `json:"assetId"`
+	CommandType string `json:"commandType"`
+	Status      string `json:"status"`
+}
+
+type accountAssetTransactionsResponse struct {
+	Total        int                          `json:"total"`
+	Page         uint32                       `json:"page"`
+	PageSize     uint32                       `json:"pageSize"`
+	Transactions []accountAssetTransactionRow `json:"transactions"`
+}
+
+// queryAccountAssetTransactions scans iroha's cursor-paginated
+// GetAccountAssetTransactions, filters the results, and cuts out the
+// [Page*PageSize, Page*PageSize+PageSize) window - the offset-style listing
+// this bridge's ABI-driven getAccountAssetTransactions doesn't offer on its
+// own, since iroha itself only exposes cursor pagination.
+func queryAccountAssetTransactions(ctx native.Context, args QueryAccountAssetTransactionsArgs) (queryAccountAssetTransactionsRets, error) {
+	if args.PageSize == 0 {
+		return queryAccountAssetTransactionsRets{}, revertError(fmt.Errorf("queryAccountAssetTransactions: pageSize must be greater than zero"))
+	}
+
+	offset := uint64(args.Page) * uint64(args.PageSize)
+	windowEnd := offset + uint64(args.PageSize)
+
+	var matched []accountAssetTransactionRow
+	firstTxHash, ordering := "", ""
+	scanPageSize := assetTransactionsScanPageSize
+	pagesScanned := 0
+
+	for {
+		pagesScanned++
+		meta := iroha_model.TxPaginationMeta{PageSize: &scanPageSize, FirstTxHash: &firstTxHash, Ordering: &ordering}
+		page, err := iroha.GetAccountAssetTransactionsPage(args.AccountId, args.AssetId, &meta)
+		if err != nil {
+			return queryAccountAssetTransactionsRets{}, revertError(err)
+		}
+
+		for _, tx := range page.Transactions {
+			row, ok := accountAssetTransactionRowFrom(tx, args.AssetId)
+			if !ok || !matchesAssetTransactionFilters(row, args) {
+				continue
+			}
+			matched = append(matched, row)
+		}
+
+		if uint64(len(matched)) >= windowEnd || !page.HasNext {
+			break
+		}
+		if pagesScanned >= maxAssetTransactionsPagesScanned {
+			ctx.Logger.Trace.Log("function", "queryAccountAssetTransactions", "account", args.AccountId,
+				"asset", args.AssetId, "warning", "maxAssetTransactionsPagesScanned reached, result may be incomplete")
+			break
+		}
+		firstTxHash = page.NextTxHash
+	}
+
+	total := len(matched)
+	windowed := []accountAssetTransactionRow{}
+	if offset < uint64(total) {
+		end := windowEnd
+		if end > uint64(total) {
+			end = uint64(total)
+		}
+		windowed = matched[offset:end]
+	}
+
+	ctx.Logger.Trace.Log("function", "queryAccountAssetTransactions", "account", args.AccountId,
+		"asset", args.AssetId, "page", args.Page, "pageSize", args.PageSize)
+
+	result, err := json.Marshal(accountAssetTransactionsResponse{
+		Total: total, Page: args.Page, PageSize: args.PageSize, Transactions: windowed,
+	})
+	if err != nil {
+		return queryAccountAssetTransactionsRets{}, revertError(err)
+	}
+	return queryAccountAssetTransactionsRets{Result: string(result)}, nil
+}
+
+// accountAssetTransactionRowFrom builds assetId's row of tx, reporting ok =
+// false if tx has no command touching assetId this bridge knows how to read
+// - either because tx predates this bridge (built by some other iroha
+// client, so its commands aren't necessarily one of the four kinds
+// tx_builder.go constructs) or because GetAccountAssetTransactions matched
+// it on a command this function doesn't recognise.
+func accountAssetTransactionRowFrom(tx *pb.Transaction, assetId string) (accountAssetTransactionRow, bool) {
+	if tx == nil || tx.Payload == nil || tx.Payload.ReducedPayload == nil {
+		return accountAssetTransactionRow{}, false
+	}
+	reduced := tx.Payload.ReducedPayload
+
+	row := accountAssetTransactionRow{
+		Timestamp: reduced.CreatedTime,
+		From:      reduced.CreatorAccountId,
+		AssetId:   assetId,
+		// GetAccountAssetTransactions only ever returns committed
+		// transactions - there is no equivalent query for rejected ones in
+		// this tree (see iroha.GetTxStatus's doc comment for the broader gap
+		// this bridge has around transaction status) - so Status is always
+		// "COMMITTED" here regardless of args.Status, which only filters.
+		Status: "COMMITTED",
+	}
+	if hash, err := iroha.ReducedPayloadHash(reduced); err == nil {
+		row.Hash = hex.EncodeToString(hash[:])
+	}
+
+	for _, command := range reduced.Commands {
+		switch c := command.Command.(type) {
+		case *pb.Command_TransferAsset:
+			if c.TransferAsset.AssetId != assetId {
+				continue
+			}
+			row.CommandType = "TransferAsset"
+			row.To = c.TransferAsset.DestAccountId
+			row.Amount = c.TransferAsset.Amount
+			return row, true
+		case *pb.Command_AddAssetQuantity:
+			if c.AddAssetQuantity.AssetId != assetId {
+				continue
+			}
+			row.CommandType = "AddAssetQuantity"
+			row.Amount = c.AddAssetQuantity.Amount
+			return row, true
+		case *pb.Command_SubtractAssetQuantity:
+			if c.SubtractAssetQuantity.AssetId != assetId {
+				continue
+			}
+			row.CommandType = "SubtractAssetQuantity"
+			row.Amount = c.SubtractAssetQuantity.Amount
+			return row, true
+		}
+	}
+	return accountAssetTransactionRow{}, false
+}
+
+// matchesAssetTransactionFilters reports whether row passes every filter
+// args set. args.MinAmount/MaxAmount compare as iroha.Amount's own
+// arbitrary-precision decimal would (see iroha/amount.go), not as a
+// machine-width integer, since a fixed-precision asset quantity can exceed
+// uint64/int64 the same way assetQuantityV2Args's doc comment explains.
+func matchesAssetTransactionFilters(row accountAssetTransactionRow, args QueryAccountAssetTransactionsArgs) bool {
+	if args.Counterparty != "" && row.To != args.Counterparty {
+		return false
+	}
+	if args.CommandType != "" && row.CommandType != args.CommandType {
+		return false
+	}
+	if args.Status != "" && row.Status != args.Status {
+		return false
+	}
+	amount, ok := new(big.Rat).SetString(row.Amount)
+	if !ok {
+		return false
+	}
+	if args.MinAmount != nil && args.MinAmount.Sign() != 0 && amount.Cmp(new(big.Rat).SetInt(args.MinAmount)) < 0 {
+		return false
+	}
+	if args.MaxAmount != nil && args.MaxAmount.Sign() != 0 && amount.Cmp(new(big.Rat).SetInt(args.MaxAmount)) > 0 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/hyperledger/burrow/permission"
+)
+
+// ServiceContractV2Address is deliberately the byte right after
+// ServiceContractAddress (see native_events.go), so an operator who already
+// knows the v1 address can find v2 by incrementing it rather than looking up
+// a second, unrelated constant.
+var ServiceContractV2Address = crypto.MustAddressFromHexString("A6ABC17819738299B3B2C1CE46D55C74F04E290D")
+
+// Ordering is the typed v2 replacement for the JSON-encoded `Ordering`
+// string GetAccountTransactionsArgs and friends take in v1. Every query
+// ServiceContractV2 exposes only ever sorts by transaction creation time, so
+// Ordering only has to say which direction that sort runs in; it is rendered
+// back into the single-field JSON array iroha_model.MakeTxPaginationMeta's
+// Ordering string decodes (see irohaOrdering below) rather than exposing
+// iroha_model.OrderingField's full field/direction pair to Solidity.
+type Ordering uint8
+
+const (
+	OrderingAsc Ordering = iota
+	OrderingDesc
+)
+
+// irohaOrdering renders o as the JSON iroha_model.MakeTxPaginationMeta's
+// Ordering string expects, or an error if o is out of range (e.g. a raw
+// integer cast Solidity doesn't itself prevent).
+func (o Ordering) irohaOrdering() (string, error) {
+	switch o {
+	case OrderingAsc:
+		return `[{"field":"CREATED_TIME","direction":"ASCENDING"}]`, nil
+	case OrderingDesc:
+		return `[{"field":"CREATED_TIME","direction":"DESCENDING"}]`, nil
+	default:
+		return "", fmt.Errorf("evm: invalid Ordering %d", o)
+	}
+}
+
+// hashToHex renders a bytes32 tx hash argument as the 64-character lowercase
+// hex string iroha's query commands identify a transaction by. The one
+// exception is the all-zero bytes32, which a caller passes to mean "no
+// cursor": it comes out as "", not 64 zeroes.
+func hashToHex(h [32]byte) string {
+	if h == ([32]byte{}) {
+		return ""
+	}
+	return hex.EncodeToString(h[:])
+}
+
+// setAccountQuorumV2Args is setAccountQuorumArgs with Quorum as the uint32
+// Solidity type it actually is, instead of a string a caller would otherwise
+// have to format/parse by hand.
+type setAccountQuorumV2Args struct {
+	Account string
+	Quorum  uint32
+}
+
+func setAccountQuorumV2(ctx native.Context, args setAccountQuorumV2Args) (setAccountQuorumRets, error) {
+	return setAccountQuorum(ctx, setAccountQuorumArgs{
+		Account: args.Account,
+		Quorum:  strconv.FormatUint(uint64(args.Quorum), 10),
+	})
+}
+
+// createAssetV2Args is createAssetArgs with Precision as the uint8 Solidity
+// type it actually is (iroha itself caps asset precision well below 256, so
+// uint8 already can't overflow what iroha would accept).
+type createAssetV2Args struct {
+	Name      string
+	Domain    string
+	Precision uint8
+}
+
+func createAssetV2(ctx native.Context, args createAssetV2Args) (createAssetRets, error) {
+	return createAsset(ctx, createAssetArgs{
+		Name:      args.Name,
+		Domain:    args.Domain,
+		Precision: strconv.FormatUint(uint64(args.Precision), 10),
+	})
+}
+
+// assetQuantityV2Args is addAssetQuantityArgs/subtractAssetQuantityArgs with
+// Amount as uint256 (via *big.Int, the same representation go-ethereum's
+// abigen uses for any Solidity integer wider than 64 bits), so an amount
+// that overflows uint64 - a legitimate fixed-precision Iroha quantity with
+// enough fractional digits - no longer silently wraps before it ever reaches
+// iroha.Amount's own big.Int mantissa (see iroha/amount.go).
+type assetQuantityV2Args struct {
+	Asset  string
+	Amount *big.Int
+}
+
+func addAssetQuantityV2(ctx native.Context, args assetQuantityV2Args) (addAssetQuantityRets, error) {
+	return addAssetQuantity(ctx, addAssetQuantityArgs{Asset: args.Asset, Amount: args.Amount.String()})
+}
+
+func subtractAssetQuantityV2(ctx native.Context, args assetQuantityV2Args) (subtractAssetQuantityRets, error) {
+	return subtractAssetQuantity(ctx, subtractAssetQuantityArgs{Asset: args.Asset, Amount: args.Amount.String()})
+}
+
+// getBlockV2Args is getBlockArgs with Height as uint256, matching
+// GetAccountTransactionsV2Args.FirstTxHeight/LastTxHeight below rather than
+// introducing a third numeric width just for this one field.
+type getBlockV2Args struct {
+	Height *big.Int
+}
+
+func getBlockV2(ctx native.Context, args getBlockV2Args) (getBlockRets, error) {
+	return getBlock(ctx, getBlockArgs{Height: args.Height.String()})
+}
+
+// GetAccountTransactionsV2Args is GetAccountTransactionsArgs with every
+// pagination field given its natural Solidity type: PageSize as uint32
+// (iroha itself takes page_size as a protobuf uint32), FirstTxHash as
+// bytes32, FirstTxTime/LastTxTime as int64 milliseconds-since-epoch (iroha's
+// own TxPaginationMeta.MakeTxPaginationMeta already parses these as signed
+// 64-bit milliseconds - see iroha_model/pagination_meta.go), FirstTxHeight/
+// LastTxHeight as uint256, and Ordering as the Ordering enum above.
+type GetAccountTransactionsV2Args struct {
+	Account       string
+	PageSize      uint32
+	FirstTxHash   [32]byte
+	FirstTxTime   int64
+	LastTxTime    int64
+	FirstTxHeight *big.Int
+	LastTxHeight  *big.Int
+	Ordering      Ordering
+}
+
+func getAccountTransactionsV2(ctx native.Context, args GetAccountTransactionsV2Args) (getAccountTransactionsRets, error) {
+	ordering, err := args.Ordering.irohaOrdering()
+	if err != nil {
+		return getAccountTransactionsRets{}, revertError(err)
+	}
+	pageSize := strconv.FormatUint(uint64(args.PageSize), 10)
+	firstTxHash := hashToHex(args.FirstTxHash)
+	firstTxTime := strconv.FormatInt(args.FirstTxTime, 10)
+	lastTxTime := strconv.FormatInt(args.LastTxTime, 10)
+	firstTxHeight := args.FirstTxHeight.String()
+	lastTxHeight := args.LastTxHeight.String()
+	return getAccountTransactions(ctx, GetAccountTransactionsArgs{
+		Account:       args.Account,
+		PageSize:      pageSize,
+		FirstTxHash:   firstTxHash,
+		FirstTxTime:   firstTxTime,
+		LastTxTime:    lastTxTime,
+		FirstTxHeight: firstTxHeight,
+		LastTxHeight:  lastTxHeight,
+		Ordering:      ordering,
+	})
+}
+
+// ServiceContractV2 is ServiceContract's typed counterpart: the same
+// underlying iroha.* calls, reached through the same v1 Go functions above,
+// but with Solidity-natural argument types instead of decimal/JSON strings.
+// It is registered at its own address (ServiceContractV2Address) rather than
+// replacing ServiceContract at its existing one, so an already-deployed
+// contract built against the string-based ABI keeps working unchanged.
+var ServiceContractV2 = native.New().MustContract("ServiceContractV2",
+	`* Typed counterpart of ServiceContract, using Solidity's own integer/
+	* bytes32 types in place of ServiceContract's decimal/JSON strings.
+	* @dev Every function here decodes its typed arguments into the strings
+	* ServiceContract's functions take and calls straight through to them.
+	`,
+	native.Function{
+		Comment: `
+			* @notice Sets account quorum
+			* @param Account account id to be used
+			* @param Quorum new quorum value
+			* @return 'true' if successful, 'false' otherwise
+			`,
+		PermFlag: IrohaSetQuorum,
+		F:        setAccountQuorumV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Creates a new iroha asset
+			* @param Name asset name
+			* @param Domain domain of the asset
+			* @param Precision precision of the asset
+			* @return 'true' if successful, 'false' otherwise
+			`,
+		PermFlag: IrohaCreateAsset,
+		F:        createAssetV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Adds asset to iroha account
+			* @param Asset name of asset
+			* @param Amount amount of asset to be added
+			* @return 'true' if successful, 'false' otherwise
+			`,
+		PermFlag: IrohaModifyAssetSupply,
+		F:        addAssetQuantityV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Subtracts asset from iroha account
+			* @param Asset name of asset
+			* @param Amount amount of asset to be subtracted
+			* @return 'true' if successful, 'false' otherwise
+			`,
+		PermFlag: IrohaModifyAssetSupply,
+		F:        subtractAssetQuantityV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Gets block
+			* @param Height height of block to be used
+			* @return the block at the given height
+			`,
+		PermFlag: permission.Call,
+		F:        getBlockV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Get transactions of the account
+			* @param Account account to be used
+			* @param PageSize, FirstTxHash, FirstTxTime, LastTxTime, FirstTxHeight, LastTxHeight, Ordering pagination fields
+			`,
+		PermFlag: permission.Call,
+		F:        getAccountTransactionsV2,
+	},
+	native.Function{
+		Comment: `
+			* @notice Transfers a certain amount of asset from source account to destination account
+			* @param Src source account address
+			* @param Dst destination account address
+			* @param Asset asset ID
+			* @param Desc description of the transfer
+			* @param Amount amount to transfer, as a uint256 scaled by the asset's own precision (see iroha.Amount)
+			* @return 'true' if successful, 'false' otherwise
+			`,
+		PermFlag: permission.Call,
+		F:        transferAssetUint,
+	},
+)
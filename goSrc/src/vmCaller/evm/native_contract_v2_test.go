@@ -0,0 +1,77 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderingIrohaOrdering(t *testing.T) {
+	asc, err := OrderingAsc.irohaOrdering()
+	require.NoError(t, err)
+	assert.Equal(t, `[{"field":"CREATED_TIME","direction":"ASCENDING"}]`, asc)
+
+	desc, err := OrderingDesc.irohaOrdering()
+	require.NoError(t, err)
+	assert.Equal(t, `[{"field":"CREATED_TIME","direction":"DESCENDING"}]`, desc)
+
+	_, err = Ordering(2).irohaOrdering()
+	assert.Error(t, err)
+}
+
+func TestHashToHexZeroIsEmpty(t *testing.T) {
+	assert.Equal(t, "", hashToHex([32]byte{}))
+}
+
+func TestHashToHex(t *testing.T) {
+	var h [32]byte
+	h[31] = 0xab
+	assert.Equal(t, hex.EncodeToString(h[:]), hashToHex(h))
+}
+
+// TestAssetQuantityV2AmountRoundTrip fuzzes *big.Int values - including ones
+// well beyond 2^63, the whole point of addAssetQuantityV2/
+// subtractAssetQuantityV2 taking a uint256 instead of a Go string a caller
+// would otherwise have to hand-format - through the same decimal-string
+// rendering assetQuantityV2Args.Amount feeds into addAssetQuantityArgs, and
+// checks the string parses back to an equal value.
+func TestAssetQuantityV2AmountRoundTrip(t *testing.T) {
+	roundTrip := func(sign bool, words []uint32) bool {
+		if len(words) == 0 {
+			words = []uint32{0}
+		}
+		n := new(big.Int)
+		for _, w := range words {
+			n.Lsh(n, 32)
+			n.Or(n, big.NewInt(int64(w)))
+		}
+		if sign {
+			n.Neg(n)
+		}
+
+		s := n.String()
+		got, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return false
+		}
+		return got.Cmp(n) == 0
+	}
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
+
+func TestAssetQuantityV2AmountRoundTripBeyondUint64(t *testing.T) {
+	n, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	require.True(t, n.Cmp(new(big.Int).SetUint64(^uint64(0))) > 0, "test amount must exceed uint64 max")
+
+	args := assetQuantityV2Args{Asset: "coin#domain", Amount: n}
+	assert.Equal(t, "123456789012345678901234567890", args.Amount.String())
+
+	got, ok := new(big.Int).SetString(args.Amount.String(), 10)
+	require.True(t, ok)
+	assert.Equal(t, 0, got.Cmp(n))
+}
@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"encoding/binary"
+
+	bin "github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/exec"
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+// ServiceContractAddress is the fixed address IsNative recognises, kept as a
+// crypto.Address so emitIrohaEvent can use it directly as a log's emitting
+// address without parsing a hex string on every call.
+var ServiceContractAddress = crypto.MustAddressFromHexString("A6ABC17819738299B3B2C1CE46D55C74F04E290C")
+
+// emitIrohaEvent appends a Solidity-ABI-compatible log for a successful Iroha
+// mutation, so a contract calling ServiceContract can observe the change
+// through eth_getLogs/transaction receipts the same way it would a Solidity
+// `emit`, instead of only the ctx.Logger.Trace line every handler already
+// writes. topic0 is keccak256(signature) per Solidity's own event-selector
+// rule (signature is given in canonical form, e.g.
+// "IrohaTransfer(string,string,string,string,string)"); every argument is
+// ABI-encoded into the log's data as a tuple of dynamic strings - none of
+// these events mark a parameter indexed, since Iroha IDs/amounts are already
+// strings and a second indexed topic buys a client little over matching on
+// topic0 alone.
+//
+// Every call site below only reaches this after its iroha.* call above has
+// already returned success, i.e. after the underlying Iroha command has
+// committed - there is no separate pending/final state to delineate once
+// that call returns.
+//
+// This already covers every state-mutating ServiceContract handler, not just
+// transferAsset/transferOtherAsset: see native_contract.go's emitIrohaEvent
+// call sites for mint (IrohaAssetQuantityAdded), burn
+// (IrohaAssetQuantitySubtracted), createAsset (IrohaAssetCreated),
+// createDomain (IrohaDomainCreated), and the rest. contract/
+// service_contracts.go's like-named transferAsset/transferOtherAsset (the
+// unreferenced, pre-rewrite generation - see that package's doc comment)
+// only ever logged through ctx.Logger.Trace and never grew this; there is no
+// second LOG4-emitting implementation to add here since this one already
+// covers the live path end to end.
+//
+// This assumes native.Context carries an EventSink field of type
+// exec.EventSink, the same interface EngineWrapper already threads into
+// w.engine.Execute as w.eventSink (see main.go); there is no vendored copy of
+// github.com/hyperledger/burrow/execution/native in this checkout to confirm
+// the field name against.
+func emitIrohaEvent(ctx native.Context, signature string, args ...string) {
+	emitLog(ctx, "emitIrohaEvent", eventTopic(signature), abiEncodeStrings(args...))
+}
+
+// eventTopic is topic0 for signature: keccak256 of its canonical form, per
+// Solidity's own event-selector rule.
+func eventTopic(signature string) bin.Word256 {
+	return bin.LeftPadWord256(crypto.Keccak256([]byte(signature)))
+}
+
+// emitLog is the shared low-level log emission emitIrohaEvent and
+// transferAssetUint's Transfer(address,address,uint256) log (see
+// native_contract_erc20.go) both build on: it logs (rather than returns) a
+// failed EventSink.Log the same way emitIrohaEvent always has, since no
+// caller here is in a position to usefully revert an otherwise-successful
+// Iroha command just because its log couldn't be recorded.
+func emitLog(ctx native.Context, function string, topics []bin.Word256, data []byte) {
+	log := &exec.LogEvent{
+		Address: ServiceContractAddress,
+		Topics:  topics,
+		Data:    data,
+	}
+	if err := ctx.EventSink.Log(log); err != nil {
+		ctx.Logger.Trace.Log("function", function, "error", err.Error())
+	}
+}
+
+// abiEncodeStrings ABI-encodes args as a tuple of dynamic `string` values:
+// one 32-byte offset per argument (the head), followed by each argument's
+// length-prefixed, zero-padded-to-32-bytes UTF-8 bytes (the tail), in the
+// same layout `abi.encode(string, string, ...)` produces in Solidity.
+func abiEncodeStrings(args ...string) []byte {
+	head := make([]byte, 32*len(args))
+	var tail []byte
+	offset := len(head)
+	for i, arg := range args {
+		putUint256(head[32*i:32*i+32], uint64(offset))
+		encoded := abiEncodeDynamicString(arg)
+		tail = append(tail, encoded...)
+		offset += len(encoded)
+	}
+	return append(head, tail...)
+}
+
+// abiEncodeDynamicString encodes a single dynamic string as its length
+// followed by its bytes, right-padded with zeros to a 32-byte boundary.
+func abiEncodeDynamicString(s string) []byte {
+	data := []byte(s)
+	padded := ((len(data) + 31) / 32) * 32
+	out := make([]byte, 32+padded)
+	putUint256(out[:32], uint64(len(data)))
+	copy(out[32:32+len(data)], data)
+	return out
+}
+
+// putUint256 writes v right-aligned into a 32-byte big-endian word, the way
+// every ABI-encoded integer (including a length or an offset) is represented.
+func putUint256(word []byte, v uint64) {
+	binary.BigEndian.PutUint64(word[len(word)-8:], v)
+}
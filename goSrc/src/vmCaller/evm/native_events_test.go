@@ -0,0 +1,32 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmthrgd/go-hex"
+)
+
+func TestAbiEncodeStrings(t *testing.T) {
+	// abi.encode("a", "bc") from solc: one offset word per arg, then each
+	// arg's length word followed by its zero-padded bytes.
+	got := abiEncodeStrings("a", "bc")
+	want := hex.MustDecodeString(
+		"0000000000000000000000000000000000000000000000000000000000000040" +
+			"0000000000000000000000000000000000000000000000000000000000000080" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"6100000000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"6263000000000000000000000000000000000000000000000000000000000000",
+	)
+	assert.Equal(t, want, got)
+}
+
+func TestAbiEncodeStringsEmpty(t *testing.T) {
+	got := abiEncodeStrings("")
+	want := hex.MustDecodeString(
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000000",
+	)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,63 @@
+package evm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+// nativeContracts is the address -> name registry RegisterNative populates
+// and isRegisteredNative consults. It replaces the pair of hardcoded
+// ServiceContractAddress/ServiceContractV2Address compares IsNative used to
+// do by hand: createNatives now discovers every native module this bridge
+// owns in one pass at node start-up and registers each of them here, the
+// way FrostFS's newInitializeContext retrieves its own list of native
+// contract hashes once, via GetNativeContracts, and caches it in a map
+// rather than re-deriving it per call.
+var nativeContracts = struct {
+	sync.Mutex
+	byAddress map[crypto.Address]string
+}{
+	byAddress: map[crypto.Address]string{},
+}
+
+// RegisterNative records addr as name's fixed address, so isRegisteredNative
+// (and therefore IsNative) recognises it without either needing to know
+// name or ns. Adding a future native module - a separate permissions
+// contract, a pagination helper contract, a future query contract - is then
+// one RegisterNative call in createNatives, not a new branch in IsNative.
+//
+// ns is required alongside addr but only checked for nil: whether addr is
+// actually backed by the *native.Natives merged into createNatives' own
+// native.Merge call is not verified. addr is taken from the caller rather
+// than resolved from ns itself, since this checkout has no vendored
+// github.com/hyperledger/burrow/execution/native source to confirm
+// *native.Natives exposes a name-to-address lookup, and guessing one on a
+// path every single EVM call depends on is a worse risk than this signature
+// looking slightly redundant.
+func RegisterNative(name string, addr crypto.Address, ns *native.Natives) (crypto.Address, error) {
+	if ns == nil {
+		return crypto.Address{}, fmt.Errorf("evm: registering native contract %q: nil natives set", name)
+	}
+
+	nativeContracts.Lock()
+	defer nativeContracts.Unlock()
+
+	if existing, ok := nativeContracts.byAddress[addr]; ok && existing != name {
+		return crypto.Address{}, fmt.Errorf(
+			"evm: native contract %q would collide with %q, both at address %s", name, existing, addr.String())
+	}
+	nativeContracts.byAddress[addr] = name
+
+	return addr, nil
+}
+
+// isRegisteredNative reports whether addr was registered by RegisterNative.
+func isRegisteredNative(addr crypto.Address) bool {
+	nativeContracts.Lock()
+	defer nativeContracts.Unlock()
+	_, ok := nativeContracts.byAddress[addr]
+	return ok
+}
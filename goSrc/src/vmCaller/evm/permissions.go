@@ -0,0 +1,76 @@
+package evm
+
+import "github.com/hyperledger/burrow/permission"
+
+// irohaPermOffset is the first bit above every PermFlag burrow's own
+// permission package currently defines (Root, Send, Call, CreateContract,
+// CreateAccount, Bond, Name, HasBase, HasRole, Proposal, Input, Batch, at
+// last count - a dozen or so bits, well below this). This checkout has no
+// vendored copy of github.com/hyperledger/burrow/permission to check that
+// count against (see this package's neighbouring placeholder-cgo doc
+// comments for why), so 32 is a deliberately generous gap rather than "one
+// past the last flag" - confirm against permission.AllPermFlags before
+// relying on these bits in a real genesis or HasPermission check.
+const irohaPermOffset = 32
+
+// Iroha-specific PermFlags, one per ServiceContract function whose misuse
+// has chain-wide consequences (governance, asset supply, peer membership,
+// role/permission grants) rather than an ordinary read or asset transfer -
+// those still gate on the generic permission.Call the way every
+// ServiceContract function used to, since singling them out buys nothing a
+// blanket Call grant doesn't already cover. Granting one of these to a
+// Burrow account works exactly like granting any other PermFlag already
+// does: set it in the account's Permissions.Base at genesis (see
+// permission.DefaultAccountPermissions's own construction for the pattern),
+// or at runtime through the setBase snative Burrow's own native.Permissions
+// contract exposes (already merged into createNatives below, gated by its
+// own Root/SetBase flags) - there is no separate setBurrowPermission entry
+// point here, since that would just be a worse-typed duplicate of setBase.
+const (
+	IrohaCreateAccount permission.PermFlag = 1 << (irohaPermOffset + iota)
+	IrohaCreateAsset
+	IrohaAddPeer
+	IrohaRemovePeer
+	IrohaGrantPermission
+	IrohaRevokePermission
+	IrohaCreateRole
+	IrohaModifyAssetSupply
+	IrohaSetQuorum
+	IrohaAppendRole
+	IrohaDetachRole
+)
+
+var allIrohaPermFlags = []permission.PermFlag{
+	IrohaCreateAccount,
+	IrohaCreateAsset,
+	IrohaAddPeer,
+	IrohaRemovePeer,
+	IrohaGrantPermission,
+	IrohaRevokePermission,
+	IrohaCreateRole,
+	IrohaModifyAssetSupply,
+	IrohaSetQuorum,
+	IrohaAppendRole,
+	IrohaDetachRole,
+}
+
+// DefaultAccountPermissions is permission.DefaultAccountPermissions with
+// every Iroha*-prefixed PermFlag above also granted (and marked set, so the
+// grant is authoritative rather than falling through to a global default).
+// Every place in this bridge that provisions a new EVM-side account
+// (VmCall's auto-created caller account, NewContract's callee account, the
+// static-call/CREATE2/conformance-harness equivalents) uses this instead of
+// burrow's own constant, specifically so introducing these PermFlags is not
+// a breaking change: any account that could call a ServiceContract function
+// before these flags existed still can after. An operator who wants one of
+// the narrower accounts this change makes possible (see permissions.go's
+// other doc comment) builds its acm.Account.Permissions by hand instead of
+// using this helper.
+var DefaultAccountPermissions = func() permission.AccountPermissions {
+	perms := permission.DefaultAccountPermissions
+	for _, flag := range allIrohaPermFlags {
+		perms.Base.Perms |= flag
+		perms.Base.SetBit |= flag
+	}
+	return perms
+}()
@@ -0,0 +1,32 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/hyperledger/burrow/permission"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIrohaPermFlagsAreDistinct(t *testing.T) {
+	flags := []permission.PermFlag{
+		IrohaCreateAccount,
+		IrohaCreateAsset,
+		IrohaAddPeer,
+		IrohaRemovePeer,
+		IrohaGrantPermission,
+		IrohaRevokePermission,
+		IrohaCreateRole,
+		IrohaModifyAssetSupply,
+		IrohaSetQuorum,
+		IrohaAppendRole,
+		IrohaDetachRole,
+	}
+
+	seen := make(map[permission.PermFlag]bool, len(flags))
+	for _, f := range flags {
+		require.NotZero(t, f)
+		require.NotEqual(t, permission.Call, f)
+		require.False(t, seen[f], "duplicate PermFlag %d", f)
+		seen[f] = true
+	}
+}
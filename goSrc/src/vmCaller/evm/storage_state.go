@@ -2,9 +2,12 @@ package evm
 
 import "C"
 import (
+	"encoding/hex"
+	"fmt"
 	"unsafe"
 
 	"vmCaller/iroha"
+	"vmCaller/state_store"
 
 	"github.com/hyperledger/burrow/acm"
 	"github.com/hyperledger/burrow/acm/acmstate"
@@ -12,27 +15,77 @@ import (
 	"github.com/hyperledger/burrow/crypto"
 )
 
+// MetadataAccount is the Iroha account under which a deployed contract
+// address's metadata hash is indexed (see SetContractMetadataHash); the
+// metadata blob a hash digests now lives in its own dedicated Ametsuchi
+// column family instead (see iroha.IrohaStorage.GetMetadata/SetMetadata).
+const MetadataAccount = "evm_metadata@evm"
+
+func metadataAddressKey(address crypto.Address) string {
+	return fmt.Sprintf("addr_%s", address.String())
+}
+
+// IrohaState embeds state_store.StateStore rather than a concrete
+// iroha.IrohaStorage, so a test can build one over
+// state_store.NewMockStateStore() and exercise IrohaState's own methods
+// below (GetAccountStats/IterateAccounts/IterateStorage) and the
+// GetMetadata/SetMetadata it promotes without a real Ametsuchi/irohad
+// instance. SetContractMetadataHash/GetContractMetadataHash are not covered
+// by this: they call iroha.SetAccountDetail/iroha.GetAccountDetailByKey
+// directly rather than the embedded StateStore. This decouples only this storage layer - the
+// rest of package evm (native_contract.go and siblings) still imports
+// vmCaller/iroha directly for every Iroha command/query helper a native
+// contract calls, so evm as a whole is not cgo-free; that would be a much
+// larger refactor than this request's "pluggable StateStore" ask.
 type IrohaState struct {
-	iroha.IrohaStorage
+	state_store.StateStore
 }
 
 // check that IrohaState implements acmstate.ReaderWriter
 var _ acmstate.ReaderWriter = &IrohaState{}
 
+// check that *iroha.IrohaStorage satisfies state_store.StateStore, so
+// NewIrohaState's real, cgo-backed construction below keeps compiling
+// without iroha/storage.go needing to change at all.
+var _ state_store.StateStore = &iroha.IrohaStorage{}
+
 func NewIrohaState(storage unsafe.Pointer) *IrohaState {
 	return &IrohaState{
-		*iroha.NewIrohaStorage(storage),
+		iroha.NewIrohaStorage(storage),
 	}
 }
 
-// mock
-func (st *IrohaState) GetMetadata(metahash acmstate.MetadataHash) (string, error) {
-	return "", nil
+// GetMetadata and SetMetadata are promoted straight through from the
+// embedded state_store.StateStore, which for the real, wired-in backend
+// (iroha.NewIrohaStorage) backs them with a dedicated Ametsuchi column
+// family (Iroha_GetMetadata/Iroha_SetMetadata) instead of reusing
+// MetadataAccount's account-detail keys the way
+// SetContractMetadataHash/GetContractMetadataHash below still do for the
+// address-to-hash association, which has no dedicated entry point of its
+// own yet.
+
+// SetContractMetadataHash associates a deployed contract address with the
+// metadata hash embedded in its bytecode's CBOR trailer, so VmGetMetadata
+// can resolve an address to its metadata without the caller needing to know
+// the hash up front.
+func (st *IrohaState) SetContractMetadataHash(address crypto.Address, metahash acmstate.MetadataHash) error {
+	return iroha.SetAccountDetail(MetadataAccount, metadataAddressKey(address), hex.EncodeToString(metahash[:]))
 }
 
-// mock
-func (st *IrohaState) SetMetadata(metahash acmstate.MetadataHash, metadata string) error {
-	return nil
+// GetContractMetadataHash returns the metadata hash associated with address
+// via SetContractMetadataHash, and false if none has been set.
+func (st *IrohaState) GetContractMetadataHash(address crypto.Address) (acmstate.MetadataHash, bool, error) {
+	var metahash acmstate.MetadataHash
+	raw, err := iroha.GetAccountDetailByKey(MetadataAccount, metadataAddressKey(address))
+	if err != nil || raw == "" {
+		return metahash, false, err
+	}
+	decoded, err := hex.DecodeString(raw)
+	if err != nil {
+		return metahash, false, err
+	}
+	copy(metahash[:], decoded)
+	return metahash, true, nil
 }
 
 // mock
@@ -0,0 +1,30 @@
+package evm
+
+import (
+	"testing"
+
+	"vmCaller/state_store"
+
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetContractMetadataHash/SetContractMetadataHash are deliberately not
+// covered here: unlike GetMetadata/SetMetadata below, they call the
+// package-level, cgo-backed iroha.SetAccountDetail/iroha.GetAccountDetailByKey
+// directly rather than going through the embedded state_store.StateStore
+// (see storage_state.go's doc comment on that pair), so swapping in a
+// MockStateStore does not make them exercisable without a real Iroha
+// backend.
+
+func TestIrohaStateMetadataPromotedThroughStateStore(t *testing.T) {
+	st := &IrohaState{StateStore: state_store.NewMockStateStore()}
+	var metahash acmstate.MetadataHash
+	copy(metahash[:], []byte("other"))
+
+	require.NoError(t, st.SetMetadata(metahash, "blob"))
+	got, err := st.GetMetadata(metahash)
+	require.NoError(t, err)
+	assert.Equal(t, "blob", got)
+}
@@ -0,0 +1,62 @@
+package evm
+
+import "encoding/json"
+
+// StructLog is one opcode-level entry of a debug_traceTransaction-style
+// trace. Populating these requires hooking Burrow's EVM at the opcode
+// level, which is not exposed through exec.EventSink (only whole Call/Log
+// events are) — the field is kept here, and always empty for now, so a
+// future opcode hook has a ready-made shape to fill in without another
+// wire-format change.
+type StructLog struct {
+	Pc      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// CallFrame is one entry of a "callTracer"-style nested trace. Event holds
+// the raw exec.CallEvent re-marshalled as-is: Burrow doesn't export the
+// exact field layout this bridge would otherwise need to hand-decode to
+// pull out from/to/input/output, so passing it through verbatim is the
+// accurate option rather than guessing at field names.
+type CallFrame struct {
+	Event     json.RawMessage `json:"event"`
+	Exception json.RawMessage `json:"exception,omitempty"`
+}
+
+// TraceResult is the debug_traceTransaction response shape: go-ethereum's
+// classic structLogs form, plus the recorded call frames for "callTracer"
+// mode callers.
+type TraceResult struct {
+	StructLogs  []StructLog `json:"structLogs"`
+	Gas         uint64      `json:"gas"`
+	Failed      bool        `json:"failed"`
+	ReturnValue string      `json:"returnValue"`
+	Calls       []CallFrame `json:"calls,omitempty"`
+}
+
+// CallFrames re-marshals every Call event the sink observed into the flat
+// CallFrame shape. It is flat rather than nested by caller/callee depth:
+// exec.CallEvent's exact fields aren't available to this package to decode,
+// so nesting by parent call isn't possible without guessing at them.
+func (ies *IrohaEventSink) CallFrames() []CallFrame {
+	frames := make([]CallFrame, 0, len(ies.calls))
+	for _, call := range ies.calls {
+		frame := CallFrame{}
+		if raw, err := json.Marshal(call.Event); err == nil {
+			frame.Event = raw
+		}
+		if call.Exception != nil {
+			if raw, err := json.Marshal(call.Exception); err == nil {
+				frame.Exception = raw
+			}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -0,0 +1,94 @@
+package evm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// queryCursorTTL bounds how long a page cursor returned by
+// getAccountTransactions stays redeemable by continueAccountTransactions,
+// so an abandoned Solidity loop's half-finished scan doesn't pin the
+// account/pageSize/ordering/iroha-cursor state below forever.
+const queryCursorTTL = 5 * time.Minute
+
+// accountTransactionsCursorKey scopes a cursor to the caller it was handed
+// to: two callers can never collide on the same random handle, and a
+// caller that guesses another's handle still can't redeem it.
+type accountTransactionsCursorKey struct {
+	caller crypto.Address
+	handle string
+}
+
+// storedAccountTransactionsQuery is everything continueAccountTransactions
+// needs to resume a getAccountTransactions scan: the account/pageSize/
+// ordering iroha needs on every page (not just the first), plus the opaque
+// iroha_model cursor string pointing at the last transaction of the page
+// this handle was returned from. Storing these three query fields under a
+// key of just the caller and handle (rather than folding a separate
+// query-fingerprint hash into the map key) already gets the same effect:
+// a handle only ever resolves back to the exact query it was issued for.
+type storedAccountTransactionsQuery struct {
+	account     string
+	pageSize    string
+	ordering    string
+	irohaCursor string
+	expiresAt   time.Time
+}
+
+var accountTransactionsCursors = struct {
+	sync.Mutex
+	byKey map[accountTransactionsCursorKey]storedAccountTransactionsQuery
+}{byKey: map[accountTransactionsCursorKey]storedAccountTransactionsQuery{}}
+
+// newQueryCursorHandle returns a random hex handle for a new page cursor,
+// the same "unguessable rather than sequential" approach iroha.BeginTx uses
+// for batch handles (see iroha/tx_registry.go) and for the same reason:
+// nothing in native.Context gives this bridge a query-scoped identifier to
+// key continuation state on instead.
+func newQueryCursorHandle() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("evm: reading random cursor handle: %s", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// storeAccountTransactionsCursor records query as redeemable by caller
+// under handle until queryCursorTTL elapses, sweeping any already-expired
+// entries first so the map doesn't grow unbounded across many scans.
+func storeAccountTransactionsCursor(caller crypto.Address, handle string, query storedAccountTransactionsQuery) {
+	query.expiresAt = time.Now().Add(queryCursorTTL)
+
+	accountTransactionsCursors.Lock()
+	defer accountTransactionsCursors.Unlock()
+	for k, v := range accountTransactionsCursors.byKey {
+		if time.Now().After(v.expiresAt) {
+			delete(accountTransactionsCursors.byKey, k)
+		}
+	}
+	accountTransactionsCursors.byKey[accountTransactionsCursorKey{caller: caller, handle: handle}] = query
+}
+
+// takeAccountTransactionsCursor removes and returns the query handle was
+// storing for caller - a cursor can only be continued from once, the same
+// one-shot-redemption shape iroha.takeTx/CommitBatch use, since after this
+// call it would just point at a page the caller has already seen.
+func takeAccountTransactionsCursor(caller crypto.Address, handle string) (storedAccountTransactionsQuery, error) {
+	accountTransactionsCursors.Lock()
+	defer accountTransactionsCursors.Unlock()
+	key := accountTransactionsCursorKey{caller: caller, handle: handle}
+	query, ok := accountTransactionsCursors.byKey[key]
+	if !ok {
+		return storedAccountTransactionsQuery{}, fmt.Errorf("evm: no open transaction cursor for this caller")
+	}
+	delete(accountTransactionsCursors.byKey, key)
+	if time.Now().After(query.expiresAt) {
+		return storedAccountTransactionsQuery{}, fmt.Errorf("evm: transaction cursor has expired")
+	}
+	return query, nil
+}
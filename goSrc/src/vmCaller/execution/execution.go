@@ -11,11 +11,11 @@ import (
 	"github.com/hyperledger/burrow/acm/acmstate"
 	"github.com/hyperledger/burrow/bcm"
 	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/errors"
 	"github.com/hyperledger/burrow/execution/evm"
 	"github.com/hyperledger/burrow/execution/exec"
 	"github.com/hyperledger/burrow/execution/native"
 	"github.com/hyperledger/burrow/logging"
-	"github.com/hyperledger/burrow/permission"
 
 	"vmCaller/blockchain"
 
@@ -44,22 +44,31 @@ type EngineWrapper struct {
 
 // Run a contract's code on an isolated and unpersisted state
 // Cannot be used to create new contracts
+//
+// gasLimit bounds the call the way main.go's VmCall hard-codes its own
+// 1,000,000 gas limit; unlike VmCall, CallSim reports back how much of it
+// was actually used (gasUsed) so callers like EthEstimateGas can binary
+// search on it. A reverted call is not reported as a Go error: it comes
+// back as a TxExecution with Exception set, matching how EthCall already
+// expects to read a sim result (see api.go's EthCall), so callers can tell
+// an out-of-gas revert (see IsOutOfGas) apart from a logic revert without
+// losing the partial gasUsed figure.
 func CallSim(reader acmstate.Reader, blockchain bcm.BlockchainInfo, from string, address crypto.Address, data []byte,
-	logger *logging.Logger) (*exec.TxExecution, error) {
+	value, gasLimit uint64, logger *logging.Logger) (*exec.TxExecution, uint64, error) {
 	m.Lock()
 	defer m.Unlock()
 	worldState := vm.NewIrohaState(iroha.StoragePointer)
 	if err := worldState.UpdateAccount(&acm.Account{
 		Address:     acm.GlobalPermissionsAddress,
 		Balance:     999999,
-		Permissions: permission.DefaultAccountPermissions,
+		Permissions: vm.DefaultAccountPermissions,
 	}); err != nil {
-		return nil, fmt.Errorf("unable to update account ")
+		return nil, 0, fmt.Errorf("unable to update account ")
 	}
 	evmCaller := native.AddressFromName(from)
 	callerAccount, err := worldState.GetAccount(evmCaller)
 	if err != nil {
-		return nil, fmt.Errorf("Passed account does not exist: %s", callerAccount)
+		return nil, 0, fmt.Errorf("Passed account does not exist: %s", callerAccount)
 	}
 
 	engine := EngineWrapper{
@@ -69,46 +78,60 @@ func CallSim(reader acmstate.Reader, blockchain bcm.BlockchainInfo, from string,
 	}
 	evmCallee := address
 	if vm.IsNative(evmCallee.String()) {
-		return nil, fmt.Errorf("The callee address %s is reserved for a native contract and cannot be called directly", evmCallee.String())
+		return nil, 0, fmt.Errorf("The callee address %s is reserved for a native contract and cannot be called directly", evmCallee.String())
 	}
 
-	output, err := engine.Execute(evmCaller, evmCallee, data)
+	output, gasUsed, err := engine.Execute(evmCaller, evmCallee, data, value, gasLimit)
 	if err != nil {
-		return nil, err
+		if exception, ok := err.(*errors.Exception); ok {
+			return &exec.TxExecution{Exception: exception}, gasUsed, nil
+		}
+		return nil, gasUsed, err
 	}
 	// create object encapsulating response
 	txe := exec.TxExecution{}
 	txe.Result = &exec.Result{Return: output}
-	return &txe, nil
+	return &txe, gasUsed, nil
 }
 
-func (w *EngineWrapper) Execute(caller, callee crypto.Address, input []byte) ([]byte, error) {
-	var gas uint64 = 1000000
+// IsOutOfGas reports whether txe (as returned by CallSim or CallCodeSim)
+// reverted because it ran out of gas, as opposed to some other revert
+// (e.g. a require()/revert() in the contract itself) that more gas would
+// not fix — the distinction EthEstimateGas needs to know whether to keep
+// raising its search range or give up and surface the revert.
+func IsOutOfGas(txe *exec.TxExecution) bool {
+	return txe != nil && txe.Exception != nil && txe.Exception.Code == errors.Codes.InsufficientGas
+}
+
+func (w *EngineWrapper) Execute(caller, callee crypto.Address, input []byte, value, gasLimit uint64) ([]byte, uint64, error) {
+	gas := gasLimit
 
 	calleeAccount, err := w.state.GetAccount(callee)
 	if err != nil {
-		return nil, fmt.Errorf("Error getting account at address %s: %s",
+		return nil, 0, fmt.Errorf("Error getting account at address %s: %s",
 			callee.String(), err.Error())
 	}
 	if calleeAccount == nil {
-		return nil, fmt.Errorf("Contract account does not exists at address %s", callee.String())
+		return nil, 0, fmt.Errorf("Contract account does not exists at address %s", callee.String())
 	}
 
 	params := engine.CallParams{
 		Caller: caller,
 		Callee: callee,
 		Input:  input,
-		Value:  0,
+		Value:  value,
 		Gas:    &gas,
 	}
-	output, err := w.engine.Execute(w.state, blockchain.New(), w.eventSink, params, calleeAccount.EVMCode)
+	output, err := w.engine.Execute(w.state, blockchain.Instance(), w.eventSink, params, calleeAccount.EVMCode)
+	// burrow's engine decrements *params.Gas as it runs, so whatever is left
+	// in gas once Execute returns tells us how much of gasLimit was spent.
+	gasUsed := gasLimit - gas
 
 	if err != nil {
-		return nil, fmt.Errorf("Error calling smart contract at address %s: %s",
-			callee.String(), err.Error())
+		return nil, gasUsed, err
 	}
 
-	return output, nil
+	return output, gasUsed, nil
 }
 
 func makeError(msg string) (*C.char, *C.char) {
@@ -124,7 +147,7 @@ func addressFromNonce(nonce string) (address crypto.Address) {
 // Run the given code on an isolated and unpersisted state
 // Cannot be used to create new contracts.
 func CallCodeSim(reader acmstate.Reader, blockchain bcm.BlockchainInfo, from string, address crypto.Address, code, data []byte,
-	logger *logging.Logger) (*exec.TxExecution, error) {
+	value, gasLimit uint64, logger *logging.Logger) (*exec.TxExecution, uint64, error) {
 
 	// Attach code to target account (overwriting target)
 	cache := acmstate.NewCache(reader)
@@ -134,7 +157,7 @@ func CallCodeSim(reader acmstate.Reader, blockchain bcm.BlockchainInfo, from str
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return CallSim(cache, blockchain, from, address, data, logger)
+	return CallSim(cache, blockchain, from, address, data, value, gasLimit, logger)
 }
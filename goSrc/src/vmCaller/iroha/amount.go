@@ -0,0 +1,145 @@
+package iroha
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Amount is a fixed-precision quantity: Mantissa is the integer value
+// scaled by 10^Precision, the same representation Iroha itself uses for
+// asset quantities (so an asset with Precision 2 stores "1.50" as mantissa
+// 150). Carrying the precision alongside the mantissa, rather than just a
+// decimal string, is what lets TransferAsset and friends catch a caller
+// passing "1.5" to a precision-0 asset instead of silently truncating it.
+type Amount struct {
+	Mantissa  *big.Int
+	Precision uint32
+}
+
+// Parse decodes a decimal string such as "1.50" into an Amount at the given
+// precision. It rejects negative amounts and strings with more fractional
+// digits than precision allows, rather than rounding or truncating them.
+func Parse(s string, precision uint32) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "-") {
+		return Amount{}, fmt.Errorf("Amount.Parse: negative amount %q is not valid", s)
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if uint32(len(fracPart)) > precision {
+		return Amount{}, fmt.Errorf(
+			"Amount.Parse: %q has more fractional digits than precision %d allows", s, precision)
+	}
+	fracPart += strings.Repeat("0", int(precision)-len(fracPart))
+
+	mantissaStr := intPart + fracPart
+	if mantissaStr == "" {
+		mantissaStr = "0"
+	}
+	mantissa, ok := new(big.Int).SetString(mantissaStr, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("Amount.Parse: invalid amount %q", s)
+	}
+	return Amount{Mantissa: mantissa, Precision: precision}, nil
+}
+
+// String renders the Amount back to the decimal string form Iroha's
+// protobuf commands expect.
+func (a Amount) String() string {
+	digits := a.Mantissa.String()
+	for uint32(len(digits)) <= a.Precision {
+		digits = "0" + digits
+	}
+	if a.Precision == 0 {
+		return digits
+	}
+	cut := len(digits) - int(a.Precision)
+	return digits[:cut] + "." + digits[cut:]
+}
+
+// Add returns a+b. Both operands must share the same precision.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.Precision != b.Precision {
+		return Amount{}, fmt.Errorf("Amount.Add: precision mismatch: %d vs %d", a.Precision, b.Precision)
+	}
+	return Amount{Mantissa: new(big.Int).Add(a.Mantissa, b.Mantissa), Precision: a.Precision}, nil
+}
+
+// Sub returns a-b. Both operands must share the same precision, and the
+// result must not be negative, matching the non-negative asset quantities
+// Iroha itself enforces.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if a.Precision != b.Precision {
+		return Amount{}, fmt.Errorf("Amount.Sub: precision mismatch: %d vs %d", a.Precision, b.Precision)
+	}
+	result := new(big.Int).Sub(a.Mantissa, b.Mantissa)
+	if result.Sign() < 0 {
+		return Amount{}, fmt.Errorf("Amount.Sub: %s - %s is negative", a.String(), b.String())
+	}
+	return Amount{Mantissa: result, Precision: a.Precision}, nil
+}
+
+var assetPrecisionCache = struct {
+	sync.RWMutex
+	byAssetID map[string]uint32
+}{byAssetID: map[string]uint32{}}
+
+// assetPrecision resolves assetID's precision via GetAssetInfo, caching the
+// result in-process since an asset's precision never changes after creation.
+func assetPrecision(assetID string) (uint32, error) {
+	assetPrecisionCache.RLock()
+	precision, cached := assetPrecisionCache.byAssetID[assetID]
+	assetPrecisionCache.RUnlock()
+	if cached {
+		return precision, nil
+	}
+
+	asset, err := GetAssetInfo(assetID)
+	if err != nil {
+		return 0, err
+	}
+
+	assetPrecisionCache.Lock()
+	assetPrecisionCache.byAssetID[assetID] = asset.Precision
+	assetPrecisionCache.Unlock()
+	return asset.Precision, nil
+}
+
+// AssetPrecision exports assetPrecision's cached GetAssetInfo lookup, for a
+// caller (evm.transferAssetUint, see evm/native_contract_erc20.go) that needs
+// an asset's precision up front to build an Amount itself, rather than
+// passing a plain decimal string through resolveAmount's pass-through case.
+func AssetPrecision(assetID string) (uint32, error) {
+	return assetPrecision(assetID)
+}
+
+// resolveAmount turns the amount argument accepted by TransferAsset and
+// friends into the decimal string Iroha's commands take. A plain string is
+// passed through unchanged, for backward compatibility with existing
+// callers. An Amount is validated against assetID's actual precision (via
+// assetPrecision) before being rendered, so a caller that built an Amount at
+// the wrong precision gets an error instead of a silently wrong transfer.
+func resolveAmount(assetID string, amount interface{}) (string, error) {
+	switch v := amount.(type) {
+	case string:
+		return v, nil
+	case Amount:
+		precision, err := assetPrecision(assetID)
+		if err != nil {
+			return "", err
+		}
+		if v.Precision != precision {
+			return "", fmt.Errorf(
+				"resolveAmount: amount has precision %d but asset %s has precision %d",
+				v.Precision, assetID, precision)
+		}
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("resolveAmount: amount must be a string or iroha.Amount, got %T", amount)
+	}
+}
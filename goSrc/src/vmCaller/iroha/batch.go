@@ -0,0 +1,248 @@
+package iroha
+
+// #cgo CFLAGS: -I ../../../../irohad
+// #cgo linux LDFLAGS: -Wl,-unresolved-symbols=ignore-all
+// #cgo darwin LDFLAGS: -Wl,-undefined,dynamic_lookup
+// #include <stdlib.h>
+// #include <string.h>
+// #include "ametsuchi/impl/burrow_storage.h"
+//
+// // Iroha_OpKind/Iroha_Op/Iroha_OpBatch/Iroha_ApplyBatch aren't part of
+// // ametsuchi/impl/burrow_storage.h yet (this checkout has no irohad/ C++
+// // tree to add them to either); they're typedef'd here the same way
+// // main.go's own const_char typedef patches in a type the real header
+// // doesn't provide, so the Go side has an exact shape for the native side
+// // to implement Iroha_ApplyBatch against. kind tags which of a/b/c/topics
+// // an op actually carries: UpdateAccount (a=address, b=account hex),
+// // RemoveAccount (a=address), SetStorage (a=address, b=key hex, c=value
+// // hex), StoreLog (a=address, b=data hex, topics=topic hexes). Like every
+// // other write entry point in this file, Iroha_ApplyBatch reports back a
+// // single Iroha_Result rather than one result per op: the batch applies
+// // atomically, so there is nothing a per-op result would let a caller do
+// // differently.
+// typedef enum {
+//   Iroha_Op_UpdateAccount = 0,
+//   Iroha_Op_RemoveAccount = 1,
+//   Iroha_Op_SetStorage = 2,
+//   Iroha_Op_StoreLog = 3,
+// } Iroha_OpKind;
+//
+// typedef struct {
+//   Iroha_OpKind kind;
+//   Iroha_CharBuffer a;
+//   Iroha_CharBuffer b;
+//   Iroha_CharBuffer c;
+//   Iroha_CharBufferArray topics;
+// } Iroha_Op;
+//
+// typedef struct {
+//   Iroha_Op *ops;
+//   unsigned long long size;
+// } Iroha_OpBatch;
+//
+// Iroha_Result Iroha_ApplyBatch(void *storage, Iroha_OpBatch batch);
+import "C"
+import (
+	"encoding/hex"
+	"unsafe"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// pendingOp is a Batch-queued write in plain Go types: nothing here crosses
+// into cgo until Flush, so queueing a whole transaction's worth of writes
+// costs no C.malloc/C.CString at all.
+type pendingOp struct {
+	kind    C.Iroha_OpKind
+	a, b, c string
+	topics  []binary.Word256
+}
+
+// Batch accumulates UpdateAccount/RemoveAccount/SetStorage/StoreLog calls
+// and applies them with a single Iroha_ApplyBatch cgo call instead of one
+// cgo call (and matching C.CString/C.free pair) per op, the way
+// IrohaStorage's own per-op methods do. Reads aren't batched: GetAccount/
+// GetStorage/GetMetadata still only make sense against committed state, so
+// callers needing them keep using the embedding IrohaStorage's own methods.
+type Batch struct {
+	storage unsafe.Pointer
+	ops     []pendingOp
+}
+
+// Batch returns a Batch that flushes against the same native storage handle
+// st already calls Iroha_GetAccount/Iroha_UpdateAccount/etc. against.
+func (st *IrohaStorage) Batch() *Batch {
+	return &Batch{storage: st.storage}
+}
+
+// Batch's UpdateAccount/RemoveAccount/SetStorage match acmstate.Writer's
+// signatures, so a Batch can stand in wherever that interface is expected -
+// in particular as the target of acmstate.Cache.Sync, which SyncFrom below
+// uses to flush a whole Cache's buffered writes through one Iroha_ApplyBatch
+// call instead of one cgo call per dirty account/storage slot.
+var _ acmstate.Writer = &Batch{}
+
+// SyncFrom drains cache's buffered UpdateAccount/RemoveAccount/SetStorage
+// calls into b (via acmstate.Cache.Sync, which replays them against any
+// acmstate.Writer) and flushes them in a single Iroha_ApplyBatch call. It
+// calls b.Flush() even if cache had nothing dirty, since Flush already
+// no-ops on an empty queue.
+//
+// Nothing in this tree's live call path constructs an acmstate.Cache around
+// vm.IrohaState for a call whose writes should actually commit - main.go's
+// vmCall executes NewContract/Execute directly against vm.IrohaState, and
+// the one existing Cache user (static_call.go's VmCallStatic) wraps a Cache
+// specifically to discard its writes, never syncing them, so a static call
+// can never produce an Iroha command. Routing main.go's commit path through
+// a Cache so SyncFrom has something real to flush would mean moving where
+// NewContract/Execute's snapshotGuard and the iroha/journal.go command
+// journal (see storage.go's Snapshot/RevertToSnapshot) take effect relative
+// to that Cache's own buffering, which this change does not attempt.
+func (b *Batch) SyncFrom(cache *acmstate.Cache) error {
+	if err := cache.Sync(b); err != nil {
+		return err
+	}
+	return b.Flush()
+}
+
+// UpdateAccount enqueues account's marshalled data for the next Flush,
+// matching IrohaStorage.UpdateAccount's own encoding.
+func (b *Batch) UpdateAccount(account *acm.Account) error {
+	marshalledData, err := account.Marshal()
+	if err != nil {
+		return err
+	}
+	b.ops = append(b.ops, pendingOp{
+		kind: C.Iroha_Op_UpdateAccount,
+		a:    account.GetAddress().String(),
+		b:    hex.EncodeToString(marshalledData),
+	})
+	return nil
+}
+
+// RemoveAccount enqueues address's removal for the next Flush.
+func (b *Batch) RemoveAccount(address crypto.Address) error {
+	b.ops = append(b.ops, pendingOp{kind: C.Iroha_Op_RemoveAccount, a: address.String()})
+	return nil
+}
+
+// SetStorage enqueues a single storage slot write for the next Flush,
+// matching IrohaStorage.SetStorage's own encoding.
+func (b *Batch) SetStorage(address crypto.Address, key binary.Word256, value []byte) error {
+	b.ops = append(b.ops, pendingOp{
+		kind: C.Iroha_Op_SetStorage,
+		a:    address.String(),
+		b:    hex.EncodeToString(key.Bytes()),
+		c:    hex.EncodeToString(value),
+	})
+	return nil
+}
+
+// StoreLog enqueues a log/receipt write for the next Flush, matching
+// IrohaStorage.StoreTxReceipt's own encoding.
+func (b *Batch) StoreLog(address crypto.Address, data []byte, topics []binary.Word256) error {
+	b.ops = append(b.ops, pendingOp{
+		kind:   C.Iroha_Op_StoreLog,
+		a:      address.String(),
+		b:      hex.EncodeToString(data),
+		topics: topics,
+	})
+	return nil
+}
+
+// Pending reports how many ops Flush would currently apply, so a caller can
+// skip the cgo crossing entirely when a transaction made no writes.
+func (b *Batch) Pending() int {
+	return len(b.ops)
+}
+
+// Flush applies every op enqueued since the last Flush in a single
+// Iroha_ApplyBatch call. The string payload for every op's a/b/c fields is
+// packed into one arena sized up front from the queued ops and allocated
+// with a single C.malloc, with each field's Iroha_CharBuffer pointing at its
+// offset into that arena instead of its own C.CString allocation. A
+// StoreLog op's topics still get their own small C.malloc'd
+// Iroha_CharBuffer array per op rather than sharing the arena (topics are
+// rare and already a tiny fixed-size array per op, so there is little left
+// to win by folding them in) - but, unlike MakeIrohaCharBufferArray's own
+// Go-slice-backed array, that array and every Iroha_CharBuffer in it lives
+// in C memory too: cOps below is itself a Go slice, and cgo forbids a Go
+// pointer (cOps[i].topics.data) pointing at memory that itself holds a Go
+// pointer, which a Go-backed array's data pointers would be. Every
+// allocation made here - the payload arena, each op's Iroha_Op, and each
+// StoreLog op's topics array - is freed once Flush returns. The queue is
+// cleared whether or not the batch commits, since a failed batch isn't
+// worth retrying op-by-op.
+func (b *Batch) Flush() error {
+	ops := b.ops
+	b.ops = nil
+	if len(ops) == 0 {
+		return nil
+	}
+
+	arenaSize := 0
+	for _, op := range ops {
+		arenaSize += len(op.a) + len(op.b) + len(op.c)
+	}
+	var arena unsafe.Pointer
+	if arenaSize > 0 {
+		arena = C.malloc(C.size_t(arenaSize))
+		defer C.free(arena)
+	}
+	offset := 0
+	pack := func(s string) C.Iroha_CharBuffer {
+		if s == "" {
+			return C.Iroha_CharBuffer{}
+		}
+		src := []byte(s)
+		dst := unsafe.Pointer(uintptr(arena) + uintptr(offset))
+		C.memmove(dst, unsafe.Pointer(&src[0]), C.size_t(len(src)))
+		buf := C.Iroha_CharBuffer{data: (*C.char)(dst), size: C.ulonglong(len(src))}
+		offset += len(src)
+		return buf
+	}
+
+	cOps := make([]C.Iroha_Op, len(ops))
+	var freeTopics []func()
+	defer func() {
+		for _, free := range freeTopics {
+			free()
+		}
+	}()
+	for i, op := range ops {
+		cOps[i] = C.Iroha_Op{kind: op.kind, a: pack(op.a), b: pack(op.b), c: pack(op.c)}
+		if len(op.topics) > 0 {
+			topics, free := packTopics(op.topics)
+			freeTopics = append(freeTopics, free)
+			cOps[i].topics = topics
+		}
+	}
+
+	_, err := handleIrohaCallResult(C.Iroha_ApplyBatch(b.storage, C.Iroha_OpBatch{
+		ops:  &cOps[0],
+		size: C.ulonglong(len(cOps)),
+	}))
+	return err
+}
+
+// packTopics C.malloc's a contiguous Iroha_CharBuffer array (plus one
+// C.CString per topic) for op's topics, entirely in C memory so it can
+// safely sit behind cOps[i].topics.data inside Flush's Go-backed cOps
+// slice. The returned func frees all of it and must be called exactly once.
+func packTopics(topics []binary.Word256) (C.Iroha_CharBufferArray, func()) {
+	arr := (*C.Iroha_CharBuffer)(C.malloc(C.size_t(len(topics)) * C.size_t(unsafe.Sizeof(C.Iroha_CharBuffer{}))))
+	slice := (*[1 << 20]C.Iroha_CharBuffer)(unsafe.Pointer(arr))[:len(topics):len(topics)]
+	for i, topic := range topics {
+		slice[i] = *MakeIrohaCharBuffer(hex.EncodeToString(topic.Bytes()))
+	}
+	free := func() {
+		for i := range slice {
+			slice[i].free()
+		}
+		C.free(unsafe.Pointer(arr))
+	}
+	return C.Iroha_CharBufferArray{data: arr, size: C.ulonglong(len(topics))}, free
+}
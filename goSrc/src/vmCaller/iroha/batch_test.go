@@ -0,0 +1,62 @@
+package iroha
+
+import (
+	"testing"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These cover Batch's queueing only, not Flush: Flush's only native
+// counterpart, Iroha_ApplyBatch, isn't implemented on the C side in this
+// checkout (see batch.go's own doc comment), the same reason
+// storage_state_test.go gives for not exercising GetContractMetadataHash/
+// SetContractMetadataHash.
+
+func TestBatchQueuesUpdateAccount(t *testing.T) {
+	b := &Batch{}
+	addr := crypto.Address{1, 2, 3}
+	require.NoError(t, b.UpdateAccount(&acm.Account{Address: addr}))
+	require.Equal(t, 1, b.Pending())
+	assert.Equal(t, addr.String(), b.ops[0].a)
+}
+
+func TestBatchQueuesRemoveAccount(t *testing.T) {
+	b := &Batch{}
+	addr := crypto.Address{4, 5, 6}
+	require.NoError(t, b.RemoveAccount(addr))
+	require.Equal(t, 1, b.Pending())
+	assert.Equal(t, addr.String(), b.ops[0].a)
+}
+
+func TestBatchQueuesSetStorage(t *testing.T) {
+	b := &Batch{}
+	addr := crypto.Address{7, 8, 9}
+	key := binary.Word256{1}
+	require.NoError(t, b.SetStorage(addr, key, []byte("value")))
+	require.Equal(t, 1, b.Pending())
+	op := b.ops[0]
+	assert.Equal(t, addr.String(), op.a)
+	assert.NotEmpty(t, op.b)
+	assert.NotEmpty(t, op.c)
+}
+
+func TestBatchQueuesStoreLog(t *testing.T) {
+	b := &Batch{}
+	addr := crypto.Address{10, 11, 12}
+	topics := []binary.Word256{binary.One256}
+	require.NoError(t, b.StoreLog(addr, []byte{0xAB}, topics))
+	require.Equal(t, 1, b.Pending())
+	assert.Equal(t, topics, b.ops[0].topics)
+}
+
+func TestBatchPendingAccumulatesAcrossOpKinds(t *testing.T) {
+	b := &Batch{}
+	require.NoError(t, b.UpdateAccount(&acm.Account{Address: crypto.Address{1}}))
+	require.NoError(t, b.RemoveAccount(crypto.Address{2}))
+	require.NoError(t, b.SetStorage(crypto.Address{3}, binary.Word256{}, []byte("x")))
+	assert.Equal(t, 3, b.Pending())
+}
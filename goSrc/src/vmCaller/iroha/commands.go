@@ -7,11 +7,15 @@ package iroha
 // #include "ametsuchi/impl/proto_specific_query_executor.h"
 import "C"
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 	"time"
 	"unsafe"
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/burrow/crypto"
 	pb "iroha.protocol"
 	"vmCaller/iroha_model"
 	"encoding/json"
@@ -23,22 +27,76 @@ var (
 	Caller               string
 )
 
+// commandExecutorGroup deduplicates calls into
+// Iroha_ProtoCommandExecutorExecute by idempotency key (see Group and
+// idempotencyKeyFor), since that C entry point itself gives no replay
+// protection.
+var commandExecutorGroup = NewGroup(1024, 30*time.Second)
+
+var idempotencyNonce uint64
+
+// NextIdempotencyKey, when non-empty, is consumed by the very next
+// makeProtobufCmdAndExecute call as its idempotency key (then reset to
+// empty) instead of the default derived one. A caller that knows it may
+// retry the same logical command — Burrow retrying a native contract call,
+// or EVM re-entrancy replaying the same call frame — should set this before
+// each attempt so only the first actually reaches
+// Iroha_ProtoCommandExecutorExecute; the rest share its cached result.
+var NextIdempotencyKey string
+
+// idempotencyKeyFor returns NextIdempotencyKey if the caller set one, or
+// else derives one from the marshaled command, Caller and a monotonic
+// nonce. The nonce means two calls that merely happen to marshal to the
+// same bytes (e.g. two legitimately separate transfers of the same amount)
+// get different default keys rather than being wrongly coalesced — only a
+// caller-supplied NextIdempotencyKey makes two calls share a result on
+// purpose.
+func idempotencyKeyFor(marshaled []byte) string {
+	if NextIdempotencyKey != "" {
+		key := NextIdempotencyKey
+		NextIdempotencyKey = ""
+		return key
+	}
+	nonce := atomic.AddUint64(&idempotencyNonce, 1)
+	payload := append(append([]byte{}, marshaled...), []byte(fmt.Sprintf("|%s|%d", Caller, nonce))...)
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
 // -----------------------Iroha commands---------------------------------------
 
 /*
 	Transfer assets between accounts
 */
-func TransferAsset(src, dst, asset, description, amount string) error {
+// TransferAsset moves amount of asset from src to dst. amount may be a
+// plain decimal string (unchecked, for backward compatibility) or an
+// Amount, which is validated against asset's actual precision first so a
+// caller can't silently truncate or misinterpret the value it sends.
+//
+// On success it records the transfer back (dst to src) as this command's
+// journal inverse (see journal.go), so a journalRevertToSnapshot spanning
+// this call undoes it.
+func TransferAsset(src, dst, asset, description string, amount interface{}) error {
+	amountStr, err := resolveAmount(asset, amount)
+	if err != nil {
+		return err
+	}
 	command := &pb.Command{Command: &pb.Command_TransferAsset{
 		TransferAsset: &pb.TransferAsset{
 			SrcAccountId:  src,
 			DestAccountId: dst,
 			AssetId:       asset,
 			Description:   description,
-			Amount:        amount,
+			Amount:        amountStr,
 		}}}
 	commandResult, err := makeProtobufCmdAndExecute(IrohaCommandExecutor, command)
-	return handleErrors(commandResult, err, "TransferAsset")
+	if cmdErr := handleErrors(commandResult, err, "TransferAsset"); cmdErr != nil {
+		return cmdErr
+	}
+	recordInverse("TransferAsset", func() error {
+		return TransferAsset(dst, src, asset, description, amountStr)
+	})
+	return nil
 }
 
 func CreateAccount(name string, domain string, key string) error {
@@ -52,35 +110,78 @@ func CreateAccount(name string, domain string, key string) error {
 	return handleErrors(commandResult, err, "CreateAccount")
 }
 
-func AddAssetQuantity(asset string, amount string) error {
+// AddAssetQuantity mints amount of asset to Caller. See TransferAsset for
+// the accepted amount types. On success it records subtracting the same
+// amount back out as this command's journal inverse (see journal.go).
+func AddAssetQuantity(asset string, amount interface{}) error {
+	amountStr, err := resolveAmount(asset, amount)
+	if err != nil {
+		return err
+	}
 	command := &pb.Command{Command: &pb.Command_AddAssetQuantity{
 		AddAssetQuantity: &pb.AddAssetQuantity{
 			AssetId: asset,
-			Amount:  amount,
+			Amount:  amountStr,
 		}}}
 	commandResult, err := makeProtobufCmdAndExecute(IrohaCommandExecutor, command)
-	return handleErrors(commandResult, err, "AddAssetQuantity")
+	if cmdErr := handleErrors(commandResult, err, "AddAssetQuantity"); cmdErr != nil {
+		return cmdErr
+	}
+	recordInverse("AddAssetQuantity", func() error {
+		return SubtractAssetQuantity(asset, amountStr)
+	})
+	return nil
 }
 
-func SubtractAssetQuantity(asset string, amount string) error {
+// SubtractAssetQuantity burns amount of asset from Caller. See
+// TransferAsset for the accepted amount types. On success it records
+// adding the same amount back as this command's journal inverse (see
+// journal.go).
+func SubtractAssetQuantity(asset string, amount interface{}) error {
+	amountStr, err := resolveAmount(asset, amount)
+	if err != nil {
+		return err
+	}
 	command := &pb.Command{Command: &pb.Command_SubtractAssetQuantity{
 		SubtractAssetQuantity: &pb.SubtractAssetQuantity{
 			AssetId: asset,
-			Amount:  amount,
+			Amount:  amountStr,
 		}}}
 	commandResult, err := makeProtobufCmdAndExecute(IrohaCommandExecutor, command)
-	return handleErrors(commandResult, err, "SubtractAssetQuantity")
+	if cmdErr := handleErrors(commandResult, err, "SubtractAssetQuantity"); cmdErr != nil {
+		return cmdErr
+	}
+	recordInverse("SubtractAssetQuantity", func() error {
+		return AddAssetQuantity(asset, amountStr)
+	})
+	return nil
 }
 
+// SetAccountDetail sets key to value on account's details. It first reads
+// key's current value so that, on success, it can record writing that
+// value back as this command's journal inverse (see journal.go) - the
+// inverse of "set" is "set back to whatever was there before", not
+// "delete", since Iroha account details have no notion of an absent key
+// distinct from an empty one here.
 func SetAccountDetail(account string, key string, value string) error {
+	previous, err := GetAccountDetailByKey(account, key)
+	if err != nil {
+		return err
+	}
 	command := &pb.Command{Command: &pb.Command_SetAccountDetail{
 		SetAccountDetail: &pb.SetAccountDetail{
 			AccountId: account,
 			Key:       key,
 			Value:     value,
 		}}}
-	commandResult, err := makeProtobufCmdAndExecute(IrohaCommandExecutor, command)
-	return handleErrors(commandResult, err, "SetAccountDetail")
+	commandResult, cmdErr := makeProtobufCmdAndExecute(IrohaCommandExecutor, command)
+	if err := handleErrors(commandResult, cmdErr, "SetAccountDetail"); err != nil {
+		return err
+	}
+	recordInverse("SetAccountDetail", func() error {
+		return SetAccountDetail(account, key, previous)
+	})
+	return nil
 }
 
 func AddPeer(address string, key string) error {
@@ -259,11 +360,11 @@ func GetAccountAssets(accountID string) ([]*pb.AccountAsset, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.AccountAsset{}, fmt.Errorf(
-			"ErrorResponse in GetIrohaAccountAssets: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []*pb.AccountAsset{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetIrohaAccountAssets",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_AccountAssetsResponse:
 		accountAssetsResponse := queryResponse.GetAccountAssetsResponse()
 		return accountAssetsResponse.AccountAssets, nil
@@ -284,11 +385,11 @@ func GetAccountDetail() (string, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return "ERROR", fmt.Errorf(
-			"ErrorResponse in GetIrohaAccountDetail: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return "ERROR", &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetIrohaAccountDetail",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_AccountDetailResponse:
 		accountDetailResponse := queryResponse.GetAccountDetailResponse()
 		return accountDetailResponse.Detail, nil
@@ -309,11 +410,11 @@ func GetAccount(accountID string) (*pb.Account, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return &pb.Account{}, fmt.Errorf(
-			"ErrorResponse in GetIrohaAccount: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return &pb.Account{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetIrohaAccount",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_AccountResponse:
 		accountResponse := queryResponse.GetAccountResponse()
 		return accountResponse.Account, nil
@@ -334,11 +435,11 @@ func GetSignatories(accountID string) ([]string, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []string{"ERROR"}, fmt.Errorf(
-			"ErrorResponse in GetAccountSignatories: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []string{"ERROR"}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetAccountSignatories",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_SignatoriesResponse:
 		signatoriesResponse := queryResponse.GetSignatoriesResponse()
 		return signatoriesResponse.Keys, nil
@@ -359,11 +460,11 @@ func GetAssetInfo(assetID string) (*pb.Asset, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return &pb.Asset{}, fmt.Errorf(
-			"ErrorResponse in GetAssetInfo: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return &pb.Asset{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetAssetInfo",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_AssetResponse:
 		assetResponse := queryResponse.GetAssetResponse()
 		return assetResponse.Asset, nil
@@ -384,11 +485,11 @@ func GetPeers() ([]*pb.Peer, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.Peer{}, fmt.Errorf(
-			"ErrorResponse in GetPeers: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []*pb.Peer{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetPeers",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_PeersResponse:
 		peersResponse := queryResponse.GetPeersResponse()
 		return peersResponse.Peers, nil
@@ -410,11 +511,11 @@ func GetBlock(height string) (*pb.Block, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return &pb.Block{}, fmt.Errorf(
-			"ErrorResponse in GetBlock: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return &pb.Block{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetBlock",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_BlockResponse:
 		blockResponse := queryResponse.GetBlockResponse()
 		return blockResponse.Block, nil
@@ -423,6 +524,21 @@ func GetBlock(height string) (*pb.Block, error) {
 	}
 }
 
+// GetBlockHash returns the content hash of the Iroha block committed at the
+// given height, computed over the marshalled block payload the same way
+// Iroha identifies a committed block.
+func GetBlockHash(height uint64) ([]byte, error) {
+	block, err := GetBlock(strconv.FormatUint(height, 10))
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(block.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(payload), nil
+}
+
 func GetRoles() ([]string, error) {
 	metaPayload := MakeQueryPayloadMeta()
 	query := &pb.Query{Payload: &pb.Query_Payload{
@@ -435,11 +551,11 @@ func GetRoles() ([]string, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []string{}, fmt.Errorf(
-			"ErrorResponse in GetRoles: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []string{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetRoles",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_RolesResponse:
 		rolesResponse := queryResponse.GetRolesResponse()
 		return rolesResponse.Roles, nil
@@ -460,11 +576,11 @@ func GetRolePermissions(role string) ([]pb.RolePermission, error) {
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []pb.RolePermission{}, fmt.Errorf(
-			"ErrorResponse in GetRolePermissions: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []pb.RolePermission{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetRolePermissions",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_RolePermissionsResponse:
 		rolePermissionsResponse := queryResponse.GetRolePermissionsResponse()
 		return rolePermissionsResponse.Permissions, nil
@@ -473,11 +589,45 @@ func GetRolePermissions(role string) ([]pb.RolePermission, error) {
 	}
 }
 
-
-func GetAccountTransactions(accountID string, txPaginationMeta *iroha_model.TxPaginationMeta) ([]*pb.Transaction, error) {
+// TransactionsPage is the richer result GetAccountTransactionsPage and
+// friends return: the page of transactions a pb.TransactionsPageResponse
+// carries, plus the total count across every page and (if there is one) the
+// hash/height of the transaction the next page should start from - exactly
+// what GetAccountTransactions used to discard by returning only
+// transactionsPageResponse.Transactions.
+type TransactionsPage struct {
+	Transactions []*pb.Transaction
+	TotalCount   uint32
+	NextTxHash   string
+	NextTxHeight uint64
+	HasNext      bool
+}
+
+// transactionsPageFrom reads resp's total-count and next-page fields into a
+// TransactionsPage alongside its Transactions. This checkout has no
+// vendored iroha.protocol source to confirm TransactionsPageResponse's exact
+// field names against (see this package's other cgo-placeholder doc
+// comments for the same constraint elsewhere); AllTransactionsSize and
+// NextTxInfo.{TxHash,TxHeight} are assumed to match real Iroha's
+// qry_responses.proto.
+func transactionsPageFrom(resp *pb.TransactionsPageResponse) TransactionsPage {
+	page := TransactionsPage{Transactions: resp.Transactions, TotalCount: resp.AllTransactionsSize}
+	if resp.NextTxInfo != nil {
+		page.NextTxHash = resp.NextTxInfo.TxHash
+		page.NextTxHeight = resp.NextTxInfo.TxHeight
+		page.HasNext = true
+	}
+	return page
+}
+
+// GetAccountTransactionsPage is GetAccountTransactions with the page's total
+// count and next-page cursor fields surfaced instead of discarded, for
+// callers (see evm.getAccountTransactions) that need to hand a caller a way
+// to fetch the next page without re-scanning from the start.
+func GetAccountTransactionsPage(accountID string, txPaginationMeta *iroha_model.TxPaginationMeta) (TransactionsPage, error) {
 	txPagination, err := iroha_model.MakeTxPaginationMeta(txPaginationMeta)
 	if err != nil {
-		return []*pb.Transaction{}, err
+		return TransactionsPage{}, err
 	}
 
 	metaPayload := MakeQueryPayloadMeta()
@@ -487,21 +637,28 @@ func GetAccountTransactions(accountID string, txPaginationMeta *iroha_model.TxPa
 			GetAccountTransactions: &pb.GetAccountTransactions{AccountId: accountID, PaginationMeta: &txPagination}}}}
 	queryResponse, err := makeProtobufQueryAndExecute(IrohaQueryExecutor, query)
 	if err != nil {
-		return []*pb.Transaction{}, err
+		return TransactionsPage{}, err
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.Transaction{}, fmt.Errorf(
-			"ErrorResponse in GetAccountTransactions: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return TransactionsPage{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetAccountTransactions",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_TransactionsPageResponse:
-		transactionsPageResponse := queryResponse.GetTransactionsPageResponse()
-		return transactionsPageResponse.Transactions, nil
+		return transactionsPageFrom(queryResponse.GetTransactionsPageResponse()), nil
 	default:
-		return []*pb.Transaction{}, fmt.Errorf("Wrong response type in GetAccountTransactions")
+		return TransactionsPage{}, fmt.Errorf("Wrong response type in GetAccountTransactions")
+	}
+}
+
+func GetAccountTransactions(accountID string, txPaginationMeta *iroha_model.TxPaginationMeta) ([]*pb.Transaction, error) {
+	page, err := GetAccountTransactionsPage(accountID, txPaginationMeta)
+	if err != nil {
+		return []*pb.Transaction{}, err
 	}
+	return page.Transactions, nil
 }
 
 func GetPendingTransactions(txPaginationMeta *iroha_model.TxPaginationMeta) ([]*pb.Transaction, error) {
@@ -520,11 +677,11 @@ func GetPendingTransactions(txPaginationMeta *iroha_model.TxPaginationMeta) ([]*
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.Transaction{}, fmt.Errorf(
-			"ErrorResponse in GetPendingTransactions: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []*pb.Transaction{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetPendingTransactions",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_PendingTransactionsPageResponse:
 		transactionsPageResponse := queryResponse.GetPendingTransactionsPageResponse()
 		return transactionsPageResponse.Transactions, nil
@@ -533,10 +690,16 @@ func GetPendingTransactions(txPaginationMeta *iroha_model.TxPaginationMeta) ([]*
 	}
 }
 
-func GetAccountAssetTransactions(accountId string, domainId string, txPaginationMeta *iroha_model.TxPaginationMeta) ([]*pb.Transaction, error) {
+// GetAccountAssetTransactionsPage is GetAccountAssetTransactions with the
+// page's total count and next-page cursor surfaced instead of discarded,
+// mirroring GetAccountTransactionsPage above - see its doc comment for why
+// transactionsPageFrom is shared between the two queries. This is what
+// evm.queryAccountAssetTransactions pages through to build an offset window
+// on top of iroha's own cursor-based pagination.
+func GetAccountAssetTransactionsPage(accountId string, domainId string, txPaginationMeta *iroha_model.TxPaginationMeta) (TransactionsPage, error) {
 	txPagination, err := iroha_model.MakeTxPaginationMeta(txPaginationMeta)
 	if err != nil {
-		return []*pb.Transaction{}, err
+		return TransactionsPage{}, err
 	}
 	metaPayload := MakeQueryPayloadMeta()
 	query := &pb.Query{Payload: &pb.Query_Payload{
@@ -545,42 +708,57 @@ func GetAccountAssetTransactions(accountId string, domainId string, txPagination
 			GetAccountAssetTransactions: &pb.GetAccountAssetTransactions{AccountId: accountId, AssetId: domainId, PaginationMeta: &txPagination}}}}
 	queryResponse, err := makeProtobufQueryAndExecute(IrohaQueryExecutor, query)
 	if err != nil {
-		return []*pb.Transaction{}, err
+		return TransactionsPage{}, err
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.Transaction{}, fmt.Errorf(
-			"ErrorResponse in GetAccountAssetTransactions: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return TransactionsPage{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetAccountAssetTransactions",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_TransactionsPageResponse:
-		transactionsPageResponse := queryResponse.GetTransactionsPageResponse()
-		return transactionsPageResponse.Transactions, nil
+		return transactionsPageFrom(queryResponse.GetTransactionsPageResponse()), nil
 	default:
-		return []*pb.Transaction{}, fmt.Errorf("Wrong response type in GetAccountAssetTransactions")
+		return TransactionsPage{}, fmt.Errorf("Wrong response type in GetAccountAssetTransactions")
 	}
 }
 
-func GetTransactions(hashes string) ([]*pb.Transaction, error) {
+func GetAccountAssetTransactions(accountId string, domainId string, txPaginationMeta *iroha_model.TxPaginationMeta) ([]*pb.Transaction, error) {
+	page, err := GetAccountAssetTransactionsPage(accountId, domainId, txPaginationMeta)
+	if err != nil {
+		return []*pb.Transaction{}, err
+	}
+	return page.Transactions, nil
+}
+
+// GetTransactionsForHashes is GetTransactions with hashes already decoded,
+// for callers (see evm.getTransactionsBatch) that already have a []string
+// and shouldn't have to round-trip it through JSON just to call GetTransactions.
+//
+// Iroha's GetTransactions query is all-or-nothing: if any hash in hashes
+// doesn't exist or isn't visible to the querying account, the whole query
+// comes back as a QueryError and none of the other, valid hashes' transactions
+// are returned either - there is no per-hash status in the response. That is
+// what makes a single bad hash in a large request useless today, and is the
+// gap evm.getTransactionsBatch's chunk-and-retry logic works around.
+func GetTransactionsForHashes(hashes []string) ([]*pb.Transaction, error) {
 	metaPayload := MakeQueryPayloadMeta()
-	var hashes_decoded []string
-	json.Unmarshal([]byte(hashes), &hashes_decoded)
 	query := &pb.Query{Payload: &pb.Query_Payload{
 		Meta: &metaPayload,
 		Query: &pb.Query_Payload_GetTransactions{
-			GetTransactions: &pb.GetTransactions{TxHashes: hashes_decoded}}}}
+			GetTransactions: &pb.GetTransactions{TxHashes: hashes}}}}
 	queryResponse, err := makeProtobufQueryAndExecute(IrohaQueryExecutor, query)
 	if err != nil {
 		return []*pb.Transaction{}, err
 	}
 	switch response := queryResponse.Response.(type) {
 	case *pb.QueryResponse_ErrorResponse:
-		return []*pb.Transaction{}, fmt.Errorf(
-			"ErrorResponse in GetTransactions: %d, %v",
-			response.ErrorResponse.ErrorCode,
-			response.ErrorResponse.Message,
-		)
+		return []*pb.Transaction{}, &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetTransactions",
+			Message: response.ErrorResponse.Message,
+		}
 	case *pb.QueryResponse_TransactionsResponse:
 		transactionsResponse := queryResponse.GetTransactionsResponse()
 		return transactionsResponse.Transactions, nil
@@ -589,6 +767,60 @@ func GetTransactions(hashes string) ([]*pb.Transaction, error) {
 	}
 }
 
+func GetTransactions(hashes string) ([]*pb.Transaction, error) {
+	var hashes_decoded []string
+	json.Unmarshal([]byte(hashes), &hashes_decoded)
+	return GetTransactionsForHashes(hashes_decoded)
+}
+
+// GetAccountDetailByKey fetches a single account-detail value set for the
+// given account under the given key, returning "" if it has never been set.
+func GetAccountDetailByKey(account, key string) (string, error) {
+	metaPayload := MakeQueryPayloadMeta()
+	query := &pb.Query{Payload: &pb.Query_Payload{
+		Meta: &metaPayload,
+		Query: &pb.Query_Payload_GetAccountDetail{
+			GetAccountDetail: &pb.GetAccountDetail{
+				OptAccountId: &pb.GetAccountDetail_AccountId{AccountId: account},
+				OptKey:       &pb.GetAccountDetail_Key{Key: key}}}}}
+	queryResponse, err := makeProtobufQueryAndExecute(IrohaQueryExecutor, query)
+	if err != nil {
+		return "", err
+	}
+	switch response := queryResponse.Response.(type) {
+	case *pb.QueryResponse_ErrorResponse:
+		if response.ErrorResponse.Reason == pb.ErrorResponse_NO_ACCOUNT_DETAIL {
+			return "", nil
+		}
+		return "", &QueryError{
+			Code:    int(response.ErrorResponse.ErrorCode),
+			Query:   "GetAccountDetailByKey",
+			Message: response.ErrorResponse.Message,
+		}
+	case *pb.QueryResponse_AccountDetailResponse:
+		var detailResponse interface{}
+		if err := json.Unmarshal([]byte(response.AccountDetailResponse.Detail), &detailResponse); err != nil {
+			return "", err
+		}
+		writers, ok := detailResponse.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("unexpected get_account_detail response type from Iroha")
+		}
+		for _, writerDetails := range writers {
+			details, ok := writerDetails.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if value, ok := details[key].(string); ok {
+				return value, nil
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("Wrong response type in GetAccountDetailByKey")
+	}
+}
+
 // -----------------------Helper functions---------------------------------------
 
 func MakeQueryPayloadMeta() pb.QueryPayloadMeta {
@@ -598,16 +830,26 @@ func MakeQueryPayloadMeta() pb.QueryPayloadMeta {
 			QueryCounter:     1}
 } 
 
-// Execute Iroha command
+// Execute Iroha command, deduplicating via commandExecutorGroup so a
+// retried or re-entrant call sharing an idempotency key (see
+// idempotencyKeyFor) reaches Iroha_ProtoCommandExecutorExecute at most once.
 func makeProtobufCmdAndExecute(cmdExecutor unsafe.Pointer, command *pb.Command) (res *C.Iroha_CommandError, err error) {
 	out, err := proto.Marshal(command)
 	if err != nil {
 		// magic constant, if not 0 => fail happened
 		return &C.Iroha_CommandError{error_code: 100}, err
 	}
-	cOut := C.CBytes(out)
-	commandResult := C.Iroha_ProtoCommandExecutorExecute(cmdExecutor, cOut, C.int(len(out)), C.CString(Caller))
-	return &commandResult, nil
+
+	key := idempotencyKeyFor(out)
+	result, err := commandExecutorGroup.Do(key, func() (interface{}, error) {
+		cOut := C.CBytes(out)
+		commandResult := C.Iroha_ProtoCommandExecutorExecute(cmdExecutor, cOut, C.int(len(out)), C.CString(Caller))
+		return &commandResult, nil
+	})
+	if err != nil {
+		return &C.Iroha_CommandError{error_code: 100}, err
+	}
+	return result.(*C.Iroha_CommandError), nil
 }
 
 // Perform Iroha query
@@ -633,12 +875,12 @@ func handleErrors(result *C.Iroha_CommandError, err error, commandName string) (
 		return err
 	}
 	if result.error_code != 0 {
-		error_extra := ""
-		error_extra_ptr := result.error_extra.toStringAndRelease()
-		if error_extra_ptr != nil {
-			error_extra = ": " + *error_extra_ptr
+		extra := ""
+		extraPtr := result.error_extra.toStringAndRelease()
+		if extraPtr != nil {
+			extra = *extraPtr
 		}
-		return fmt.Errorf("Error executing %s command: %s", commandName, error_extra)
+		return &CommandError{Code: int(result.error_code), Command: commandName, Extra: extra}
 	}
 	return nil
 }
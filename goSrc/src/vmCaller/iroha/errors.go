@@ -0,0 +1,79 @@
+package iroha
+
+import "fmt"
+
+// CommandError is the typed error every command wrapper in this package
+// returns, carrying Iroha's numeric error_code and extra diagnostic string
+// instead of collapsing them into an opaque message, so callers (the EVM
+// contract layer in particular) can branch with errors.As/errors.Is instead
+// of parsing Error() text.
+type CommandError struct {
+	Code    int
+	Command string
+	Extra   string
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("Error executing %s command: code %d", e.Command, e.Code)
+	if e.Extra != "" {
+		msg += ": " + e.Extra
+	}
+	return msg
+}
+
+// Is lets errors.Is(err, ErrNoPermission) etc. match any CommandError with
+// the same Code regardless of which command produced it: Iroha reuses
+// small error_code ranges (e.g. "not enough X") across several command
+// types rather than giving every failure a globally unique code.
+func (e *CommandError) Is(target error) bool {
+	t, ok := target.(*CommandError)
+	return ok && e.Code == t.Code
+}
+
+// QueryError is CommandError's counterpart for query responses.
+type QueryError struct {
+	Code    int
+	Query   string
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("ErrorResponse in %s: %d, %s", e.Query, e.Code, e.Message)
+}
+
+func (e *QueryError) Is(target error) bool {
+	t, ok := target.(*QueryError)
+	return ok && e.Code == t.Code
+}
+
+// The sentinels below are this bridge's best-effort mapping of the
+// error_code values Iroha 1.x is documented to return for commands and
+// queries in this package. This tree doesn't vendor irohad's
+// command_error/query_error enum headers (only the opaque
+// Iroha_CommandError/QueryResponse wire types are visible here), so this
+// list isn't guaranteed complete or exactly right — anything not covered
+// still comes back as a CommandError/QueryError with Code/Command(or Query)
+// set, so callers aren't blocked on this list being exhaustive.
+var (
+	ErrNoPermission        = &CommandError{Code: 2}
+	ErrInsufficientBalance = &CommandError{Code: 1, Command: "TransferAsset"}
+	ErrInvalidSignatures   = &CommandError{Code: 4}
+
+	ErrQueryNoPermission = &QueryError{Code: 2}
+	ErrQueryNotFound     = &QueryError{Code: 4}
+)
+
+// RevertReason renders err as an "IROHA:<code>:<name>" string so a
+// Solidity caller that only sees a revert reason string can still branch
+// on the underlying Iroha error code without this bridge having to pass
+// structured data across the EVM ABI boundary.
+func RevertReason(err error) string {
+	switch e := err.(type) {
+	case *CommandError:
+		return fmt.Sprintf("IROHA:%d:%s", e.Code, e.Command)
+	case *QueryError:
+		return fmt.Sprintf("IROHA:%d:%s", e.Code, e.Query)
+	default:
+		return err.Error()
+	}
+}
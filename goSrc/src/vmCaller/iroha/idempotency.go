@@ -0,0 +1,111 @@
+package iroha
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// call is a single in-flight or recently-completed invocation tracked by a
+// Group.
+type call struct {
+	wg     sync.WaitGroup
+	result interface{}
+	err    error
+	doneAt time.Time
+}
+
+// Group deduplicates calls that share a key: a caller that calls Do with a
+// key that's already in flight blocks on that in-flight call and shares its
+// result instead of invoking fn again ("single-flight"), and a caller that
+// calls Do with a key that finished within ttl gets the cached result
+// without invoking fn at all. Keys are evicted oldest-first once the store
+// holds more than capacity, so it can't grow without bound.
+//
+// This ports the idempotency-group idea from the bytom codebase's
+// sync/idempotency package to this module's interface{}-based style (it
+// predates Go generics). Results are stored as interface{} rather than a
+// typed field so this file can stay free of the cgo import that
+// commands.go needs for the concrete *C.Iroha_CommandError it stores here.
+type Group struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	calls    map[string]*call
+	order    *list.List
+	elems    map[string]*list.Element
+}
+
+// NewGroup creates a Group holding at most capacity keys, each short-
+// circuiting repeat Do calls for ttl after it completes.
+func NewGroup(capacity int, ttl time.Duration) *Group {
+	return &Group{
+		ttl:      ttl,
+		capacity: capacity,
+		calls:    map[string]*call{},
+		order:    list.New(),
+		elems:    map[string]*list.Element{},
+	}
+}
+
+// Do runs fn for key, unless key is already in flight or completed within
+// ttl, in which case it returns that call's result instead of running fn
+// again. This is best-effort, not a strict guarantee: a key that's just
+// expired out of the cache as two callers race on it can still let fn run
+// twice, which is acceptable here since the C ABI this guards gives no
+// stronger replay protection to begin with.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		if time.Since(c.doneAt) < g.ttl {
+			return c.result, c.err
+		}
+		g.mu.Lock()
+		if existing, ok := g.calls[key]; ok && existing == c {
+			g.evict(key)
+		}
+		g.mu.Unlock()
+	} else {
+		g.mu.Unlock()
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.mu.Lock()
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.result, c.err = fn()
+	c.doneAt = time.Now()
+	c.wg.Done()
+
+	g.mu.Lock()
+	g.touch(key)
+	g.mu.Unlock()
+
+	return c.result, c.err
+}
+
+// touch marks key as most-recently-completed and evicts the oldest entries
+// past capacity. Callers must hold g.mu.
+func (g *Group) touch(key string) {
+	if elem, ok := g.elems[key]; ok {
+		g.order.MoveToBack(elem)
+	} else {
+		g.elems[key] = g.order.PushBack(key)
+	}
+	for g.order.Len() > g.capacity {
+		g.evict(g.order.Front().Value.(string))
+	}
+}
+
+// evict drops key from every index. Callers must hold g.mu.
+func (g *Group) evict(key string) {
+	delete(g.calls, key)
+	if elem, ok := g.elems[key]; ok {
+		g.order.Remove(elem)
+		delete(g.elems, key)
+	}
+}
@@ -0,0 +1,123 @@
+package iroha
+
+import (
+	"fmt"
+	"sync"
+)
+
+// inverseOp is one journal entry's undo action.
+type inverseOp struct {
+	name  string
+	apply func() error
+}
+
+// journal records, in order, the inverse of every mutating command
+// TransferAsset/AddAssetQuantity/SubtractAssetQuantity/SetAccountDetail
+// issues through the cgo command executor
+// (Iroha_ProtoCommandExecutorExecute). Unlike IrohaStorage's own
+// BurrowStorage-backed account/storage writes, those commands commit
+// straight through with no shadow write-set of their own for
+// IrohaStorage.Snapshot/RevertToSnapshot to undo (see that method's doc
+// comment for why it's a separate Ametsuchi column family entirely).
+// journalSnapshot/journalRevertToSnapshot give Snapshot/RevertToSnapshot a
+// second, Go-side mechanism to fold into the same id, so a REVERT at the one
+// call boundary this bridge's own Go code controls - main.go's
+// snapshotGuard, wrapping each top-level VmCall/VmCallPrivate - undoes these
+// commands too, instead of leaving a contract-observed revert's TransferAsset/
+// SetAccountDetail side effects committed.
+//
+// Replaying an inverse is itself a newly committed command, not a rollback
+// Iroha performs for us: there is no savepoint/rollback primitive in this
+// bridge's C ABI to build a true undo on (the same constraint Tx.Commit's
+// own doc comment describes for batched commands), so this is best-effort -
+// a crash between a forward command committing and its inverse committing
+// would still leave the forward effect in place - and an inverse that
+// itself fails to commit stops the replay rather than continuing past a
+// partially-undone batch (see journalRevertToSnapshot).
+var journal = struct {
+	sync.Mutex
+	ops       []inverseOp
+	replaying bool
+}{}
+
+// recordInverse appends apply as the undo for the command that was just
+// committed, unless it's itself being called while journalRevertToSnapshot
+// is already replaying older inverses - otherwise every reverted command
+// would re-record itself as a new, never-cleaned-up inverse.
+func recordInverse(name string, apply func() error) {
+	journal.Lock()
+	defer journal.Unlock()
+	if journal.replaying {
+		return
+	}
+	journal.ops = append(journal.ops, inverseOp{name: name, apply: apply})
+}
+
+// journalSnapshot returns an id identifying the journal's current length,
+// for journalRevertToSnapshot to later roll back to.
+func journalSnapshot() int {
+	journal.Lock()
+	defer journal.Unlock()
+	return len(journal.ops)
+}
+
+// journalSnapshotsByNativeID lets IrohaStorage.Snapshot/RevertToSnapshot/
+// CommitSnapshot (storage.go) fold a journalSnapshot id into the same
+// uint64 id Iroha_Snapshot already returns, instead of changing every
+// snapshotter caller (main.go's snapshotGuard, via vm.IrohaState) to thread
+// a second id through.
+var journalSnapshotsByNativeID = struct {
+	sync.Mutex
+	ids map[uint64]int
+}{ids: map[uint64]int{}}
+
+// rememberJournalSnapshot records that nativeID (an Iroha_Snapshot id) was
+// taken alongside journalID (a journalSnapshot id), for a later
+// RevertToSnapshot/CommitSnapshot call to look up by nativeID alone.
+func rememberJournalSnapshot(nativeID uint64, journalID int) {
+	journalSnapshotsByNativeID.Lock()
+	defer journalSnapshotsByNativeID.Unlock()
+	journalSnapshotsByNativeID.ids[nativeID] = journalID
+}
+
+// takeJournalSnapshot looks up and forgets the journalSnapshot id recorded
+// for nativeID, reporting false if Snapshot was never called for it (e.g.
+// Iroha_Snapshot itself failed, so Snapshot returned before reaching
+// rememberJournalSnapshot).
+func takeJournalSnapshot(nativeID uint64) (int, bool) {
+	journalSnapshotsByNativeID.Lock()
+	defer journalSnapshotsByNativeID.Unlock()
+	id, ok := journalSnapshotsByNativeID.ids[nativeID]
+	delete(journalSnapshotsByNativeID.ids, nativeID)
+	return id, ok
+}
+
+// journalRevertToSnapshot replays, in LIFO order, every inverse recorded
+// since id was taken, then truncates the journal back to id. It stops at
+// the first inverse that fails to commit, the same best-effort-not-atomic
+// sequencing Tx.Commit uses for a batch's forward commands, since there is
+// no Iroha-side rollback to fall back on for a failed inverse either.
+func journalRevertToSnapshot(id int) error {
+	journal.Lock()
+	if id > len(journal.ops) {
+		journal.Unlock()
+		return fmt.Errorf("iroha: journal snapshot %d is newer than the current journal (len %d)", id, len(journal.ops))
+	}
+	ops := append([]inverseOp{}, journal.ops[id:]...)
+	journal.ops = journal.ops[:id]
+	journal.replaying = true
+	journal.Unlock()
+
+	defer func() {
+		journal.Lock()
+		journal.replaying = false
+		journal.Unlock()
+	}()
+
+	for i := len(ops) - 1; i >= 0; i-- {
+		if err := ops[i].apply(); err != nil {
+			return fmt.Errorf("iroha: reverting %s: %w", ops[i].name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package iroha
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetJournal clears journal state between tests, since journal/
+// journalSnapshotsByNativeID are package-level (mirroring the real
+// Iroha-backed command executor/storage they journal for, which are
+// also package-level).
+func resetJournal(t *testing.T) {
+	t.Helper()
+	journal.Lock()
+	journal.ops = nil
+	journal.replaying = false
+	journal.Unlock()
+}
+
+func TestJournalRevertToSnapshotUndoesInLIFOOrder(t *testing.T) {
+	resetJournal(t)
+
+	var undone []string
+	record := func(name string) {
+		recordInverse(name, func() error {
+			undone = append(undone, name)
+			return nil
+		})
+	}
+
+	record("A")
+	s1 := journalSnapshot()
+	record("B")
+	s2 := journalSnapshot()
+	record("C")
+
+	require.NoError(t, journalRevertToSnapshot(s2))
+	assert.Equal(t, []string{"C"}, undone)
+	assert.Equal(t, s2, journalSnapshot(), "reverting to s2 should leave the journal at length s2")
+
+	require.NoError(t, journalRevertToSnapshot(s1))
+	assert.Equal(t, []string{"C", "B"}, undone)
+	assert.Equal(t, s1, journalSnapshot())
+}
+
+func TestJournalRevertToSnapshotStopsAtFirstFailure(t *testing.T) {
+	resetJournal(t)
+
+	var applied []string
+	s0 := journalSnapshot()
+	recordInverse("first", func() error {
+		applied = append(applied, "first")
+		return nil
+	})
+	recordInverse("second", func() error {
+		applied = append(applied, "second")
+		return fmt.Errorf("second failed to commit")
+	})
+	recordInverse("third", func() error {
+		applied = append(applied, "third")
+		return nil
+	})
+
+	err := journalRevertToSnapshot(s0)
+	require.Error(t, err)
+	assert.Equal(t, []string{"third", "second"}, applied,
+		"replay is LIFO and must stop once an inverse fails, leaving the batch partially undone")
+}
+
+func TestRecordInverseSuppressedWhileReplaying(t *testing.T) {
+	resetJournal(t)
+
+	s0 := journalSnapshot()
+	recordInverse("forward", func() error {
+		// A real inverse (e.g. TransferAsset's) calls back into a command
+		// that itself calls recordInverse; that nested recordInverse must
+		// be a no-op during replay, or every revert would grow the journal
+		// by one instead of shrinking it back to s0.
+		recordInverse("nested-during-replay", func() error { return nil })
+		return nil
+	})
+
+	require.NoError(t, journalRevertToSnapshot(s0))
+	assert.Equal(t, s0, journalSnapshot(), "replay must not leave behind entries recorded during the replay itself")
+}
+
+func TestSnapshotFoldingByNativeID(t *testing.T) {
+	resetJournal(t)
+	journalSnapshotsByNativeID.Lock()
+	journalSnapshotsByNativeID.ids = map[uint64]int{}
+	journalSnapshotsByNativeID.Unlock()
+
+	var undone []string
+	recordInverse("before-snapshot", func() error {
+		undone = append(undone, "before-snapshot")
+		return nil
+	})
+
+	const nativeID uint64 = 42
+	rememberJournalSnapshot(nativeID, journalSnapshot())
+	recordInverse("after-snapshot", func() error {
+		undone = append(undone, "after-snapshot")
+		return nil
+	})
+
+	journalID, ok := takeJournalSnapshot(nativeID)
+	require.True(t, ok)
+	require.NoError(t, journalRevertToSnapshot(journalID))
+	assert.Equal(t, []string{"after-snapshot"}, undone)
+
+	_, ok = takeJournalSnapshot(nativeID)
+	assert.False(t, ok, "takeJournalSnapshot must forget nativeID once taken")
+}
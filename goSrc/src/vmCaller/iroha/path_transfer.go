@@ -0,0 +1,51 @@
+package iroha
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TransferAssetPath moves sendAsset from src to dst via a chain of
+// TransferAsset hops through path (each element an intermediate Iroha
+// account ID acting as a forwarding/liquidity account: src -> path[0] ->
+// ... -> path[len-1] -> dst), submitted as one Tx batch so the whole chain
+// commits or stops together (see iroha.Tx for how much atomicity this
+// actually gives).
+//
+// Iroha's command set has no native asset-exchange/DEX primitive — only
+// TransferAsset, which moves a single asset unchanged — so this only
+// supports sendAsset == destAsset path transfers; a request to convert
+// between two different assets returns an error rather than inventing an
+// exchange command this Iroha version doesn't have. With no conversion and
+// no fee model, the amount delivered at dst equals the amount debited at
+// src, so the whole chain moves sendMax and the call aborts up front if
+// sendMax can't satisfy destMin, before submitting anything.
+func TransferAssetPath(src, dst, sendAsset, sendMax, destAsset, destMin string, path []string) error {
+	if sendAsset != destAsset {
+		return fmt.Errorf("TransferAssetPath: cannot convert %s to %s: Iroha has no native asset-exchange command", sendAsset, destAsset)
+	}
+
+	sendMaxAmount, err := strconv.ParseFloat(sendMax, 64)
+	if err != nil {
+		return fmt.Errorf("TransferAssetPath: invalid sendMax %q: %s", sendMax, err.Error())
+	}
+	destMinAmount, err := strconv.ParseFloat(destMin, 64)
+	if err != nil {
+		return fmt.Errorf("TransferAssetPath: invalid destMin %q: %s", destMin, err.Error())
+	}
+	if destMinAmount > sendMaxAmount {
+		return fmt.Errorf("TransferAssetPath: sendMax %s cannot satisfy destMin %s with no conversion available", sendMax, destMin)
+	}
+
+	hops := append([]string{src}, path...)
+	hops = append(hops, dst)
+
+	tx := NewTx()
+	for i := 0; i+1 < len(hops); i++ {
+		description := fmt.Sprintf("path transfer %s -> %s via %s", src, dst, sendAsset)
+		tx.Transfer(hops[i], hops[i+1], sendAsset, description, sendMax)
+	}
+
+	_, err = tx.Commit()
+	return err
+}
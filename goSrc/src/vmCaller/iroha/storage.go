@@ -8,9 +8,11 @@ package iroha
 import "C"
 import (
 	"encoding/hex"
+	"strconv"
 	"unsafe"
 
 	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
 	"github.com/hyperledger/burrow/binary"
 	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/burrow/execution/errors"
@@ -143,6 +145,112 @@ func (st *IrohaStorage) SetStorage(address crypto.Address, key binary.Word256, v
 	return nil
 }
 
+// GetMetadata and SetMetadata call Iroha_GetMetadata/Iroha_SetMetadata, two
+// new cgo entry points mirroring Iroha_GetStorage/Iroha_SetStorage's own
+// (Iroha_CharBuffer in, Iroha_Result out) shape, backed by a dedicated
+// Ametsuchi column family keyed by the hex-encoded MetadataHash rather than
+// an ordinary account's storage or detail entries. This checkout has no
+// irohad/ C++ tree (ametsuchi/impl/burrow_storage.h, included above, isn't
+// present here either), so these declarations can't be built or linked in
+// this sandbox; they're written to the same contract
+// Iroha_GetStorage/Iroha_SetStorage already use so the native side has an
+// exact shape to implement against.
+func (st *IrohaStorage) GetMetadata(metahash acmstate.MetadataHash) (string, error) {
+	cMetahash := MakeIrohaCharBuffer(hex.EncodeToString(metahash[:]))
+	defer cMetahash.free()
+	metadata, err := handleIrohaCallResult(C.Iroha_GetMetadata(st.storage, *cMetahash))
+
+	if err != nil {
+		return "", err
+	}
+	if metadata == nil {
+		return "", nil
+	}
+	return *metadata, nil
+}
+
+func (st *IrohaStorage) SetMetadata(metahash acmstate.MetadataHash, metadata string) error {
+	cMetahash := MakeIrohaCharBuffer(hex.EncodeToString(metahash[:]))
+	defer cMetahash.free()
+	cMetadata := MakeIrohaCharBuffer(metadata)
+	defer cMetadata.free()
+	_, err := handleIrohaCallResult(C.Iroha_SetMetadata(st.storage, *cMetahash, *cMetadata))
+	return err
+}
+
+// Snapshot and RevertToSnapshot call new cgo entry points Iroha_Snapshot/
+// Iroha_RevertToSnapshot, which push/pop a shadow write-set on top of the
+// Ametsuchi burrow-storage column families GetAccount/GetStorage/GetMetadata
+// already read through, so a write made after Snapshot can be undone without
+// it ever having left Ametsuchi's in-memory layer. Snapshot follows
+// GetStorage/GetMetadata's (Iroha_CharBuffer in, Iroha_Result out) shape
+// rather than the request's literal "-> uint64", so a failure (e.g. the
+// shadow write-set stack is exhausted) can be reported the same way every
+// other entry point here reports one, instead of introducing a one-off raw-
+// integer cgo return convention; the id itself is just the decimal string in
+// result.data. This checkout has no irohad/ C++ tree (ametsuchi/impl/
+// burrow_storage.h, included above, isn't present here either), so these
+// declarations can't be built or linked in this sandbox; they're written to
+// the same contract GetMetadata/SetMetadata already use so the native side
+// has an exact shape to implement against.
+//
+// This shadow write-set is a separate Ametsuchi column family from the one
+// Iroha_ProtoCommandExecutorExecute commits TransferAsset/AddAssetQuantity/
+// SubtractAssetQuantity/SetAccountDetail commands through, so on its own it
+// cannot undo those. Snapshot additionally takes journalSnapshot() (see
+// journal.go) and remembers it against the returned native id, and
+// RevertToSnapshot/CommitSnapshot below fold that second snapshot into the
+// same id, so one Snapshot/RevertToSnapshot pair - the one main.go's
+// snapshotGuard already takes around each top-level VmCall/VmCallPrivate -
+// undoes both kinds of write together.
+func (st *IrohaStorage) Snapshot() (uint64, error) {
+	idStr, err := handleIrohaCallResult(C.Iroha_Snapshot(st.storage))
+	if err != nil {
+		return 0, err
+	}
+	if idStr == nil {
+		return 0, errors.Errorf(errors.Codes.ExecutionReverted, "Iroha_Snapshot returned no id")
+	}
+	id, err := strconv.ParseUint(*idStr, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	rememberJournalSnapshot(id, journalSnapshot())
+	return id, nil
+}
+
+func (st *IrohaStorage) RevertToSnapshot(id uint64) error {
+	cID := MakeIrohaCharBuffer(strconv.FormatUint(id, 10))
+	defer cID.free()
+	_, err := handleIrohaCallResult(C.Iroha_RevertToSnapshot(st.storage, *cID))
+
+	if journalID, ok := takeJournalSnapshot(id); ok {
+		if journalErr := journalRevertToSnapshot(journalID); err == nil {
+			err = journalErr
+		}
+	}
+	return err
+}
+
+// CommitSnapshot calls Iroha_CommitSnapshot to fold id's shadow write-set
+// frame into its parent frame (or into the real Ametsuchi state, if id was
+// the outermost frame) and pop it, the counterpart to RevertToSnapshot for
+// the case where the call that took the snapshot succeeded. Without this, a
+// successful call's frame would never be reclaimed and the shadow write-set
+// stack would grow without bound across calls until it hit the very
+// exhaustion error Snapshot's own doc comment anticipates. It also drops
+// id's journalSnapshot bookkeeping entry: the journal entries it covers need
+// no further action to "commit" - they simply stay in the journal, folded
+// into whatever outer snapshot (if any) is still open, the same way the
+// native shadow write-set folds into its parent frame.
+func (st *IrohaStorage) CommitSnapshot(id uint64) error {
+	takeJournalSnapshot(id)
+	cID := MakeIrohaCharBuffer(strconv.FormatUint(id, 10))
+	defer cID.free()
+	_, err := handleIrohaCallResult(C.Iroha_CommitSnapshot(st.storage, *cID))
+	return err
+}
+
 func (st *IrohaStorage) StoreTxReceipt(address crypto.Address, hex_data []byte, topics []binary.Word256) error {
 	cAddress := MakeIrohaCharBuffer(address.String())
 	defer cAddress.free()
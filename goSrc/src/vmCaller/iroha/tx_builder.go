@@ -0,0 +1,125 @@
+package iroha
+
+import pb "iroha.protocol"
+
+// CommandResult is the outcome of one command within a Tx: the command
+// name it was built from, and the error handleErrors produced for it (nil
+// on success).
+type CommandResult struct {
+	Name  string
+	Error error
+}
+
+// Tx accumulates a sequence of pb.Commands and submits them one at a time
+// against Caller, the way a hand-written sequence of iroha.TransferAsset /
+// iroha.SetAccountDetail / etc. calls already would.
+//
+// Iroha's C ABI exposed to this bridge (Iroha_ProtoCommandExecutorExecute,
+// see makeProtobufCmdAndExecute) only executes one command at a time — there
+// is no Iroha_ProtoTransactionExecutorExecute entry point in this tree to
+// submit a pb.Transaction as a single atomic unit. So Commit runs each
+// queued command in order and stops at the first failure rather than
+// continuing to run commands that were meant to be conditioned on it. This
+// is best-effort sequencing, not atomicity: Iroha does not expose a
+// savepoint/rollback primitive through this C ABI, so any commands that
+// already succeeded before the failing one stay committed. Callers that
+// need true all-or-nothing semantics must structure their commands so nothing
+// earlier in the sequence has an undesirable effect on its own.
+type Tx struct {
+	commands []namedCommand
+}
+
+type namedCommand struct {
+	name    string
+	command *pb.Command
+}
+
+// NewTx starts a new batch for Caller. The Caller global is read at Commit
+// time by makeProtobufCmdAndExecute, same as every other command wrapper.
+func NewTx() *Tx {
+	return &Tx{}
+}
+
+func (tx *Tx) add(name string, command *pb.Command) *Tx {
+	tx.commands = append(tx.commands, namedCommand{name: name, command: command})
+	return tx
+}
+
+// Transfer queues a TransferAsset command.
+func (tx *Tx) Transfer(src, dst, asset, description, amount string) *Tx {
+	return tx.add("TransferAsset", transferCommand(src, dst, asset, description, amount))
+}
+
+// AddAssetQuantity queues an AddAssetQuantity command.
+func (tx *Tx) AddAssetQuantity(asset string, amount string) *Tx {
+	return tx.add("AddAssetQuantity", addAssetQuantityCommand(asset, amount))
+}
+
+// SubtractAssetQuantity queues a SubtractAssetQuantity command.
+func (tx *Tx) SubtractAssetQuantity(asset string, amount string) *Tx {
+	return tx.add("SubtractAssetQuantity", subtractAssetQuantityCommand(asset, amount))
+}
+
+// SetAccountDetail queues a SetAccountDetail command.
+func (tx *Tx) SetAccountDetail(account string, key string, value string) *Tx {
+	return tx.add("SetAccountDetail", setAccountDetailCommand(account, key, value))
+}
+
+// transferCommand, addAssetQuantityCommand, subtractAssetQuantityCommand,
+// and setAccountDetailCommand build the pb.Command the like-named Tx method
+// above queues. They are also what BuildUnsignedTx (see tx_mst.go) uses to
+// turn a command list into pb.Commands without going through a Tx at all,
+// since BuildUnsignedTx never calls Commit/makeProtobufCmdAndExecute.
+func transferCommand(src, dst, asset, description, amount string) *pb.Command {
+	return &pb.Command{Command: &pb.Command_TransferAsset{
+		TransferAsset: &pb.TransferAsset{
+			SrcAccountId:  src,
+			DestAccountId: dst,
+			AssetId:       asset,
+			Description:   description,
+			Amount:        amount,
+		}}}
+}
+
+func addAssetQuantityCommand(asset, amount string) *pb.Command {
+	return &pb.Command{Command: &pb.Command_AddAssetQuantity{
+		AddAssetQuantity: &pb.AddAssetQuantity{
+			AssetId: asset,
+			Amount:  amount,
+		}}}
+}
+
+func subtractAssetQuantityCommand(asset, amount string) *pb.Command {
+	return &pb.Command{Command: &pb.Command_SubtractAssetQuantity{
+		SubtractAssetQuantity: &pb.SubtractAssetQuantity{
+			AssetId: asset,
+			Amount:  amount,
+		}}}
+}
+
+func setAccountDetailCommand(account, key, value string) *pb.Command {
+	return &pb.Command{Command: &pb.Command_SetAccountDetail{
+		SetAccountDetail: &pb.SetAccountDetail{
+			AccountId: account,
+			Key:       key,
+			Value:     value,
+		}}}
+}
+
+// Commit executes every queued command in order against IrohaCommandExecutor,
+// stopping at the first one that fails. It returns a CommandResult per
+// command that was actually attempted (so callers can see how far the batch
+// got) and a single aggregate error, which is the failing command's error,
+// or nil if every command succeeded.
+func (tx *Tx) Commit() ([]CommandResult, error) {
+	results := make([]CommandResult, 0, len(tx.commands))
+	for _, nc := range tx.commands {
+		commandResult, err := makeProtobufCmdAndExecute(IrohaCommandExecutor, nc.command)
+		cmdErr := handleErrors(commandResult, err, nc.name)
+		results = append(results, CommandResult{Name: nc.name, Error: cmdErr})
+		if cmdErr != nil {
+			return results, cmdErr
+		}
+	}
+	return results, nil
+}
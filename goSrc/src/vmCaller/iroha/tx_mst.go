@@ -0,0 +1,166 @@
+package iroha
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "iroha.protocol"
+)
+
+// UnsignedTxCommand is one entry of the commandsJSON array BuildUnsignedTx
+// takes: the same (Kind, fields) shape as a single Tx.Transfer/
+// AddAssetQuantity/SubtractAssetQuantity/SetAccountDetail call - the four
+// command kinds this bridge already knows how to build (see tx_builder.go
+// and tx_registry.go's matching Queue* helpers) - serialized so a whole
+// command list can be handed over in one EVM call instead of one
+// queue call per command.
+type UnsignedTxCommand struct {
+	Kind        string `json:"kind"`
+	Src         string `json:"src,omitempty"`
+	Dst         string `json:"dst,omitempty"`
+	Asset       string `json:"asset,omitempty"`
+	Description string `json:"description,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+	Account     string `json:"account,omitempty"`
+	Key         string `json:"key,omitempty"`
+	Value       string `json:"value,omitempty"`
+}
+
+// toProto builds the pb.Command c describes, reusing the same constructors
+// Tx's methods build their queued commands from.
+func (c UnsignedTxCommand) toProto() (*pb.Command, error) {
+	switch c.Kind {
+	case "transferAsset":
+		return transferCommand(c.Src, c.Dst, c.Asset, c.Description, c.Amount), nil
+	case "addAssetQuantity":
+		return addAssetQuantityCommand(c.Asset, c.Amount), nil
+	case "subtractAssetQuantity":
+		return subtractAssetQuantityCommand(c.Asset, c.Amount), nil
+	case "setAccountDetail":
+		return setAccountDetailCommand(c.Account, c.Key, c.Value), nil
+	default:
+		return nil, fmt.Errorf("unsupported command kind %q", c.Kind)
+	}
+}
+
+// BuildUnsignedTx decodes commandsJSON (a JSON array of UnsignedTxCommand)
+// into an unsigned pb.Transaction for creatorAccountID with the given
+// quorum, and returns its reduced-payload hash (what each co-signer's
+// signature must cover) alongside the marshalled, still-unsigned
+// pb.Transaction bytes a caller collects signatures against off-chain
+// before calling SubmitSignedTx.
+//
+// The hash here is sha256 of the marshalled ReducedPayload, not iroha's own
+// transaction-hashing scheme (real iroha hashes a transaction with SHA3-256
+// over a slightly different encoding) - this checkout has no vendored
+// iroha.protocol/shared_model code to confirm that scheme against, so a
+// txHash from this function will not match the hash iroha itself would
+// report for the same transaction once submitted. Wiring this up to match
+// is blocked on the same missing proto/hashing reference GetTxStatus's and
+// SubmitSignedTx's doc comments describe.
+func BuildUnsignedTx(creatorAccountID string, quorum uint32, commandsJSON string) ([32]byte, []byte, error) {
+	var commands []UnsignedTxCommand
+	if err := json.Unmarshal([]byte(commandsJSON), &commands); err != nil {
+		return [32]byte{}, nil, fmt.Errorf("iroha: invalid commandsJSON: %v", err)
+	}
+	if len(commands) == 0 {
+		return [32]byte{}, nil, fmt.Errorf("iroha: commandsJSON must contain at least one command")
+	}
+
+	pbCommands := make([]*pb.Command, len(commands))
+	for i, c := range commands {
+		cmd, err := c.toProto()
+		if err != nil {
+			return [32]byte{}, nil, fmt.Errorf("iroha: command %d: %v", i, err)
+		}
+		pbCommands[i] = cmd
+	}
+
+	reduced := &pb.Transaction_Payload_ReducedPayload{
+		Commands:         pbCommands,
+		CreatorAccountId: creatorAccountID,
+		CreatedTime:      uint64(time.Now().UnixNano() / int64(time.Millisecond)),
+		Quorum:           quorum,
+	}
+	hash, err := ReducedPayloadHash(reduced)
+	if err != nil {
+		return [32]byte{}, nil, err
+	}
+
+	tx := &pb.Transaction{Payload: &pb.Transaction_Payload{ReducedPayload: reduced}}
+	payload, err := proto.Marshal(tx)
+	if err != nil {
+		return [32]byte{}, nil, fmt.Errorf("iroha: marshalling unsigned transaction: %v", err)
+	}
+
+	return hash, payload, nil
+}
+
+// ReducedPayloadHash is the same sha256-of-marshalled-ReducedPayload BuildUnsignedTx
+// hashes a transaction it is building with, exported so a caller that already
+// has a transaction's ReducedPayload - rather than building one from scratch -
+// can derive the same locally-computed identifier (see
+// evm.queryAccountAssetTransactions, which has no other way to give a
+// pb.Transaction a hash: neither the transaction itself nor a
+// GetAccountAssetTransactions response carries one). As BuildUnsignedTx's own
+// doc comment notes, this is not iroha's real SHA3-256 transaction hash, so a
+// hash from here will never match what iroha itself reports for the same
+// transaction.
+func ReducedPayloadHash(reduced *pb.Transaction_Payload_ReducedPayload) ([32]byte, error) {
+	reducedBytes, err := proto.Marshal(reduced)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("iroha: marshalling transaction payload: %v", err)
+	}
+	return sha256.Sum256(reducedBytes), nil
+}
+
+// TxState mirrors iroha's own transaction status enum, to the extent this
+// bridge can observe it at all (see GetTxStatus).
+type TxState uint8
+
+const (
+	TxStateStatelessValid TxState = iota
+	TxStateStatefulValid
+	TxStateMSTPending
+	TxStateMSTExpired
+	TxStateCommitted
+	TxStateRejected
+)
+
+// SubmitSignedTx is meant to relay a pb.Transaction (payload) plus an
+// RLP-encoded signature bundle collected off-chain from MST co-signers, the
+// way a real MST-capable iroha client submits a partially-signed
+// transaction for the network to finish collecting quorum on.
+//
+// It cannot do that in this tree: this package's only way to reach iroha is
+// the C ABI its cgo preamble includes (ametsuchi/impl/
+// proto_command_executor.h's Iroha_ProtoCommandExecutorExecute, used by
+// makeProtobufCmdAndExecute, and proto_specific_query_executor.h's query
+// equivalent - see commands.go) - there is no Iroha_ProtoTransactionExecutorExecute
+// or similar entry point to submit a whole signed pb.Transaction, the same
+// gap Tx.Commit's own doc comment describes for unsigned, single-signer
+// command batches. Until that entry point exists on the native side,
+// SubmitSignedTx can only report that it is unsupported rather than
+// pretend to relay signaturesRLP anywhere.
+func SubmitSignedTx(payload []byte, signaturesRLP []byte) error {
+	return fmt.Errorf("iroha: SubmitSignedTx is not supported by this bridge: no C ABI entry point exists to submit a signed pb.Transaction (see this function's doc comment)")
+}
+
+// GetTxStatus is meant to report txHash's current TxState the way iroha's
+// own GetTransactionStatus query would.
+//
+// It cannot do that in this tree for the same reason SubmitSignedTx can't
+// submit: proto_specific_query_executor.h's C ABI only accepts the
+// pb.Query payloads this package's other Get* functions already build
+// (GetAccountTransactions, GetTransactions by hash, and so on), none of
+// which is a status-by-hash lookup, and there is no
+// Iroha_ProtoTxStatusExecute or equivalent to add one against. A real
+// implementation also needs iroha's own transaction-hashing scheme to make
+// sense of a bytes32 txHash at all (see BuildUnsignedTx's doc comment on
+// the same gap), which this tree has no vendored reference for either.
+func GetTxStatus(txHash [32]byte) (TxState, error) {
+	return 0, fmt.Errorf("iroha: GetTxStatus is not supported by this bridge: no transaction-status query entry point exists (see this function's doc comment)")
+}
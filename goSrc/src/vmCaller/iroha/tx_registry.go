@@ -0,0 +1,163 @@
+package iroha
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxBatchCommands caps how many commands a single Tx opened through
+// BeginTx may accumulate before queueing starts rejecting further appends,
+// so a runaway or malicious contract can't grow an open batch without
+// bound while it sits in the registry waiting for CommitBatch/AbortBatch.
+const MaxBatchCommands = 100
+
+// batchHandleTTL bounds how long a handle BeginTx returns stays valid if
+// neither CommitBatch nor AbortBatch is ever called for it. Nothing ties
+// registry cleanup to the EVM call's own revert/snapshot path - BeginTx/
+// withOpenTx/takeTx only ever see the raw handle a contract passes back in,
+// not the native.Context or acmstate.ReaderWriter snapshotGuard (see
+// main.go) operates on, so there is no revert signal to hook here without
+// threading a lot more than a handle across that boundary. A TTL swept
+// lazily on every BeginTx call instead bounds how many abandoned entries
+// (a batch begun by a call that then reverts, runs out of gas, or simply
+// never resolves it) can pile up in the registry, without needing a
+// background goroutine or any new cross-package wiring.
+const batchHandleTTL = 10 * time.Minute
+
+// registryEntry pairs a Tx with the time BeginTx created it, so
+// sweepExpiredLocked can tell an abandoned entry from a live one.
+type registryEntry struct {
+	tx        *Tx
+	createdAt time.Time
+}
+
+// openTxRegistry holds every Tx started by BeginTx and not yet resolved by
+// CommitBatch/AbortBatch, keyed by the random handle BeginTx handed back to
+// the caller. A package-level registry (rather than a field on
+// native.Context) is what's available here: there is no vendored copy of
+// github.com/hyperledger/burrow/execution/native in this checkout to add a
+// batch-handle field to Context with, and ServiceContract's functions only
+// ever receive a native.Context, not anything this bridge's own code
+// defines (see native_events.go's EventSink-field doc comment for the same
+// constraint elsewhere). The registry is protected by a mutex rather than
+// assumed single-threaded, since Burrow's own EVM makes no promise that two
+// calls can't overlap.
+var openTxRegistry = struct {
+	sync.Mutex
+	byHandle map[uint64]*registryEntry
+}{byHandle: map[uint64]*registryEntry{}}
+
+// sweepExpiredLocked removes every entry older than batchHandleTTL. Callers
+// must already hold openTxRegistry's lock.
+func sweepExpiredLocked() {
+	now := time.Now()
+	for handle, entry := range openTxRegistry.byHandle {
+		if now.Sub(entry.createdAt) >= batchHandleTTL {
+			delete(openTxRegistry.byHandle, handle)
+		}
+	}
+}
+
+// BeginTx starts a new empty Tx and returns a random, unguessable handle
+// for it. The handle (rather than a sequential counter) is this bridge's
+// stand-in for the per-call-frame isolation the request asks for: without
+// a tx-scoped identifier available from native.Context to key the registry
+// on instead, a random 64-bit handle at least means a re-entrant call can't
+// interfere with another contract's open batch without first being handed,
+// or guessing, its handle.
+func BeginTx() uint64 {
+	var b [8]byte
+	// crypto/rand.Read only returns an error if the system CSPRNG is
+	// unavailable, which would mean this whole process's environment is
+	// broken; panicking matches how the rest of this package treats that
+	// class of failure (e.g. MakeIrohaCharBuffer never checks C.CString for
+	// nil either).
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("iroha.BeginTx: reading random handle: %s", err))
+	}
+	handle := binary.BigEndian.Uint64(b[:])
+
+	openTxRegistry.Lock()
+	defer openTxRegistry.Unlock()
+	sweepExpiredLocked()
+	openTxRegistry.byHandle[handle] = &registryEntry{tx: NewTx(), createdAt: time.Now()}
+	return handle
+}
+
+// withOpenTx runs f against handle's open Tx, enforcing MaxBatchCommands
+// before f is allowed to queue anything more onto it. It is how every
+// Queue* helper below reaches the Tx a raw handle refers to without
+// exposing the registry itself.
+func withOpenTx(handle uint64, f func(tx *Tx)) error {
+	openTxRegistry.Lock()
+	defer openTxRegistry.Unlock()
+	entry, ok := openTxRegistry.byHandle[handle]
+	if !ok || time.Since(entry.createdAt) >= batchHandleTTL {
+		delete(openTxRegistry.byHandle, handle)
+		return fmt.Errorf("iroha: no open batch for handle %d", handle)
+	}
+	if len(entry.tx.commands) >= MaxBatchCommands {
+		return fmt.Errorf("iroha: batch %d already holds the maximum of %d commands", handle, MaxBatchCommands)
+	}
+	f(entry.tx)
+	return nil
+}
+
+// QueueTransfer appends a TransferAsset command to handle's open Tx,
+// instead of committing it within the same call the way Tx.Transfer's other
+// callers (transferAssetAndSetAccountDetail and TransferAssetPath) do.
+func QueueTransfer(handle uint64, src, dst, asset, description, amount string) error {
+	return withOpenTx(handle, func(tx *Tx) { tx.Transfer(src, dst, asset, description, amount) })
+}
+
+// QueueAddAssetQuantity appends an AddAssetQuantity command to handle's
+// open Tx.
+func QueueAddAssetQuantity(handle uint64, asset, amount string) error {
+	return withOpenTx(handle, func(tx *Tx) { tx.AddAssetQuantity(asset, amount) })
+}
+
+// QueueSubtractAssetQuantity appends a SubtractAssetQuantity command to
+// handle's open Tx.
+func QueueSubtractAssetQuantity(handle uint64, asset, amount string) error {
+	return withOpenTx(handle, func(tx *Tx) { tx.SubtractAssetQuantity(asset, amount) })
+}
+
+// QueueSetAccountDetail appends a SetAccountDetail command to handle's open
+// Tx.
+func QueueSetAccountDetail(handle uint64, account, key, value string) error {
+	return withOpenTx(handle, func(tx *Tx) { tx.SetAccountDetail(account, key, value) })
+}
+
+// CommitBatch submits handle's queued commands as Tx.Commit would and
+// removes handle from the registry either way: a committed batch can't be
+// committed twice, and a failed one isn't worth retrying through the same
+// handle (see Tx.Commit's own doc comment on what "atomic" actually means
+// here).
+func CommitBatch(handle uint64) ([]CommandResult, error) {
+	tx, err := takeTx(handle)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Commit()
+}
+
+// AbortBatch discards handle's queued commands without submitting any of
+// them.
+func AbortBatch(handle uint64) error {
+	_, err := takeTx(handle)
+	return err
+}
+
+func takeTx(handle uint64) (*Tx, error) {
+	openTxRegistry.Lock()
+	defer openTxRegistry.Unlock()
+	entry, ok := openTxRegistry.byHandle[handle]
+	delete(openTxRegistry.byHandle, handle)
+	if !ok || time.Since(entry.createdAt) >= batchHandleTTL {
+		return nil, fmt.Errorf("iroha: no open batch for handle %d", handle)
+	}
+	return entry.tx, nil
+}
@@ -14,6 +14,8 @@ import (
 	"syscall"
 	"time"
 
+	"vmCaller/blockchain"
+	"vmCaller/iroha"
 	irohaRpc "vmCaller/rpc"
 
 	"github.com/hyperledger/burrow/bcm"
@@ -51,6 +53,7 @@ type Kernel struct {
 	Launchers      []process.Launcher
 	State          *state.State
 	Blockchain     *bcm.Blockchain
+	VMBlockchain   *blockchain.Blockchain
 	Node           *tendermint.Node
 	Transactor     *execution.Transactor
 	RunID          simpleuuid.UUID // Time-based UUID randomly generated each time Burrow is started
@@ -68,6 +71,7 @@ type Kernel struct {
 	timeoutFactor  float64
 	shutdownNotify chan struct{}
 	shutdownOnce   sync.Once
+	apiNamespaces  map[string]bool
 }
 
 // NewKernel initializes an empty kernel
@@ -80,6 +84,7 @@ func NewKernel(dbDir string) (*Kernel, error) {
 		Logger:         logging.NewNoopLogger(),
 		RunID:          runID,
 		Emitter:        event.NewEmitter(),
+		VMBlockchain:   blockchain.Instance(),
 		processes:      make(map[string]process.Process),
 		listeners:      make(map[string]net.Listener),
 		shutdownNotify: make(chan struct{}),
@@ -87,6 +92,54 @@ func NewKernel(dbDir string) (*Kernel, error) {
 	}, err
 }
 
+// RebuildBlockchain reconstructs the BLOCKHASH ring buffer by asking Iroha
+// for the hashes of up to the last 256 blocks committed at or before
+// topHeight. It should be called once at kernel start, before any EVM
+// execution can occur, so BLOCKHASH has real ancestry to answer with.
+func (kern *Kernel) RebuildBlockchain(topHeight uint64) error {
+	start := uint64(0)
+	if topHeight >= blockchain.RingSize {
+		start = topHeight - blockchain.RingSize + 1
+	}
+	for height := start; height <= topHeight; height++ {
+		hash, err := iroha.GetBlockHash(height)
+		if err != nil {
+			return fmt.Errorf("RebuildBlockchain: could not fetch hash for block %d: %v", height, err)
+		}
+		if err := kern.VMBlockchain.CommitBlock(height, hash, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAPIs restricts which JSON-RPC namespaces (e.g. "eth", "net", "web3",
+// "txpool", "admin", "debug") the kernel's RPC listeners will serve,
+// mirroring Burrow's --http.api flag. Passing no namespaces leaves every
+// namespace enabled, which is also the default for a freshly built Kernel.
+func (kern *Kernel) SetAPIs(namespaces ...string) {
+	kern.apiNamespaces = make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		kern.apiNamespaces[ns] = true
+	}
+}
+
+// HasAPI reports whether the given namespace should be served. With no
+// restriction configured via SetAPIs, every namespace is enabled.
+func (kern *Kernel) HasAPI(namespace string) bool {
+	if len(kern.apiNamespaces) == 0 {
+		return true
+	}
+	return kern.apiNamespaces[namespace]
+}
+
+// OnCommit is the commit-path hook invoked whenever Iroha commits a new
+// block. It keeps the BLOCKHASH ring buffer, together with
+// LastBlockHeight/LastBlockTime, current for subsequent EVM executions.
+func (kern *Kernel) OnCommit(height uint64, hash []byte, blockTime time.Time) error {
+	return kern.VMBlockchain.CommitBlock(height, hash, blockTime)
+}
+
 // SetLogger initializes the kernel with the provided logger
 func (kern *Kernel) SetLogger(logger *logging.Logger) {}
 
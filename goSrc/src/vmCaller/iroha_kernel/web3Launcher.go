@@ -12,7 +12,7 @@ import (
 func Web3Launcher(kern *Kernel, conf *rpc.ServerConfig) process.Launcher {
 	return process.Launcher{
 		Name:    "Web3ProcessName",
-		Enabled: conf.Enabled,
+		Enabled: conf.Enabled && (kern.HasAPI("eth") || kern.HasAPI("net") || kern.HasAPI("web3")),
 		Launch: func() (process.Process, error) {
 			listener, err := process.ListenerFromAddress(fmt.Sprintf("%s:%s", conf.ListenHost, conf.ListenPort))
 			if err != nil {
@@ -0,0 +1,30 @@
+package iroha_model
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	ordering := `[{"field":"CREATED_TIME","direction":"ASCENDING"}]`
+	cursor := MakeCursor(ordering, 42, 1700000000000, "abc123")
+
+	got, err := decodeCursor(cursor, ordering)
+	if err != nil {
+		t.Fatalf("decodeCursor returned error: %v", err)
+	}
+	if got.Height != 42 || got.TimeMs != 1700000000000 || got.TxHash != "abc123" {
+		t.Fatalf("decodeCursor = %+v, want height=42 timeMs=1700000000000 txHash=abc123", got)
+	}
+}
+
+func TestCursorRejectsMismatchedOrdering(t *testing.T) {
+	cursor := MakeCursor(`[{"field":"CREATED_TIME","direction":"ASCENDING"}]`, 1, 0, "h")
+	if _, err := decodeCursor(cursor, `[{"field":"CREATED_TIME","direction":"DESCENDING"}]`); err == nil {
+		t.Fatal("expected decodeCursor to reject a cursor issued for a different ordering")
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	cursor := MakeCursor(`[]`, 1, 0, "h") + "tampered"
+	if _, err := decodeCursor(cursor, `[]`); err == nil {
+		t.Fatal("expected decodeCursor to reject a tampered cursor")
+	}
+}
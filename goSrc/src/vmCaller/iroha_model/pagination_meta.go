@@ -18,6 +18,13 @@ type TxPaginationMeta struct{
 	LastTxTime *string
 	FirstTxHeight *string
 	LastTxHeight *string
+	// Cursor, if set, is an opaque token produced by MakeCursor from the last
+	// transaction of a previous page. It takes precedence over FirstTxHash/
+	// FirstTxTime/FirstTxHeight, and LastTxTime/LastTxHeight are ignored
+	// while it is set: those fields stay around for callers that haven't
+	// moved to cursors yet, but a page fetched between two commits can shift
+	// them out from under a caller, which is exactly what Cursor avoids.
+	Cursor *string
 }
 
 type OrderingField struct {
@@ -44,6 +51,23 @@ func MakeTxPaginationMeta(txMeta *TxPaginationMeta) (pb.TxPaginationMeta, error)
 	}else{
 		TxPaginationMeta.PageSize = uint32(size)
 	}
+	// check cursor - if present it overrides FirstTxHash/FirstTxTime/FirstTxHeight below,
+	// since it carries the same information in a single tamper-evident token;
+	// LastTxTime/LastTxHeight are not applied when a cursor is present
+	if txMeta.Cursor != nil && len(*txMeta.Cursor) != 0 {
+		cursor, err := decodeCursor(*txMeta.Cursor, *txMeta.Ordering)
+		if err != nil {
+			return TxPaginationMeta, fmt.Errorf("Invalid cursor: %v", err)
+		}
+		firstTime, err := ptypes.TimestampProto(time.Unix(0, cursor.TimeMs*int64(time.Millisecond)))
+		if err != nil {
+			return TxPaginationMeta, fmt.Errorf("Invalid cursor: %v", err)
+		}
+		TxPaginationMeta.OptFirstTxTime = &pb.TxPaginationMeta_FirstTxTime{firstTime}
+		TxPaginationMeta.OptFirstTxHeight = &pb.TxPaginationMeta_FirstTxHeight{cursor.Height}
+		TxPaginationMeta.FirstTxHash = cursor.TxHash
+		return TxPaginationMeta, nil
+	}
 	// check firstTxTime
 	if txMeta.FirstTxTime!=nil && len(*txMeta.FirstTxTime) != 0 { //check if value is passed
 		firstTimeMs, err := strconv.ParseInt(*txMeta.FirstTxTime, 10, 64) //parse it
@@ -82,5 +106,8 @@ func MakeTxPaginationMeta(txMeta *TxPaginationMeta) (pb.TxPaginationMeta, error)
 			TxPaginationMeta.OptLastTxHeight = &pb.TxPaginationMeta_LastTxHeight{lastHeightInt}
 		}
 	}
+	if txMeta.FirstTxHash != nil && len(*txMeta.FirstTxHash) != 0 {
+		TxPaginationMeta.FirstTxHash = *txMeta.FirstTxHash
+	}
 	return TxPaginationMeta, nil
 }
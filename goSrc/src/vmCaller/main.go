@@ -7,6 +7,7 @@ import (
 	"unsafe"
 
 	"vmCaller/blockchain"
+	"vmCaller/compiler"
 	vm "vmCaller/evm"
 	"vmCaller/iroha"
 
@@ -17,7 +18,6 @@ import (
 	"github.com/hyperledger/burrow/execution/evm"
 	"github.com/hyperledger/burrow/execution/exec"
 	"github.com/hyperledger/burrow/execution/native"
-	"github.com/hyperledger/burrow/permission"
 	"github.com/tmthrgd/go-hex"
 )
 
@@ -37,10 +37,87 @@ type EngineWrapper struct {
 	engine    Engine
 	state     acmstate.ReaderWriter
 	eventSink exec.EventSink
+
+	// privateState is set only by VmCallPrivate: when non-nil, every read
+	// and write NewContract/Execute makes targets it instead of state (see
+	// targetState), isolating the call's effects to this bridge's private
+	// storage domain (see evm/private_state.go).
+	privateState acmstate.ReaderWriter
+}
+
+// targetState is whichever of state/privateState this call actually reads
+// and writes: privateState for a VmCallPrivate call, state otherwise.
+func (w *EngineWrapper) targetState() acmstate.ReaderWriter {
+	if w.privateState != nil {
+		return w.privateState
+	}
+	return w.state
+}
+
+// snapshotter is implemented by an acmstate.ReaderWriter that can push a
+// shadow write-set frame and later discard it (currently only *vm.IrohaState,
+// via iroha.IrohaStorage.Snapshot/RevertToSnapshot); NewContract/Execute use
+// it, where available, to undo a call's account/storage writes on error
+// instead of leaving Ametsuchi with a half-deployed contract or a partially
+// applied sub-call.
+type snapshotter interface {
+	Snapshot() (uint64, error)
+	RevertToSnapshot(id uint64) error
+	CommitSnapshot(id uint64) error
+}
+
+// snapshotGuard snapshots state if it supports snapshotter and returns a
+// func that, when deferred, reverts that snapshot if *err is non-nil once
+// the caller returns, or commits it (folding its frame into the parent
+// instead of leaving it pinned forever) otherwise. If state doesn't support
+// snapshotter, or taking the snapshot itself fails (e.g. there is no native
+// Ametsuchi shadow-write-set implementation backing it, which is the case
+// everywhere in this checkout today - see Snapshot's doc comment), the
+// returned func is a no-op, so a call behaves exactly as it did before this
+// existed.
+func snapshotGuard(state acmstate.ReaderWriter, err *error) func() {
+	snap, ok := state.(snapshotter)
+	if !ok {
+		return func() {}
+	}
+	id, snapErr := snap.Snapshot()
+	if snapErr != nil {
+		return func() {}
+	}
+	return func() {
+		if *err != nil {
+			if revertErr := snap.RevertToSnapshot(id); revertErr != nil {
+				fmt.Println("Error reverting to snapshot", id, ":", revertErr.Error())
+			}
+			return
+		}
+		if commitErr := snap.CommitSnapshot(id); commitErr != nil {
+			fmt.Println("Error committing snapshot", id, ":", commitErr.Error())
+		}
+	}
 }
 
 //export VmCall
 func VmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	return vmCall(input, caller, callee, nonce, commandExecutor, queryExecutor, storage, false)
+}
+
+// VmCallPrivate is VmCall's Quorum-style private-transaction sibling: the
+// call executes against a vm.PrivateState overlay instead of the public
+// IrohaState, so every write it makes lands in this bridge's own "private"
+// Iroha account rather than the real, publicly readable EVM account/
+// storage space (see evm/private_state.go). privateFor is accepted for
+// parity with Quorum's own privateFor: [pubkey] field, but it is not
+// enforced as an access-control list here: there is no existing recipient/
+// participant concept in this bridge to check it against, so any caller
+// able to invoke VmCallPrivate can currently read back what it wrote.
+//
+//export VmCallPrivate
+func VmCallPrivate(input, caller, callee, nonce, privateFor *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	return vmCall(input, caller, callee, nonce, commandExecutor, queryExecutor, storage, true)
+}
+
+func vmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer, private bool) (*C.char, *C.char) {
 	// Update global executors and Caller
 	iroha.IrohaCommandExecutor = commandExecutor
 	iroha.IrohaQueryExecutor = queryExecutor
@@ -51,7 +128,7 @@ func VmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryEx
 	if err := worldState.UpdateAccount(&acm.Account{
 		Address:     acm.GlobalPermissionsAddress,
 		Balance:     999999,
-		Permissions: permission.DefaultAccountPermissions,
+		Permissions: vm.DefaultAccountPermissions,
 	}); err != nil {
 		return makeError(err.Error())
 	}
@@ -66,7 +143,7 @@ func VmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryEx
 	if callerAccount == nil {
 		if err := worldState.UpdateAccount(&acm.Account{
 			Address:     evmCaller,
-			Permissions: permission.DefaultAccountPermissions,
+			Permissions: vm.DefaultAccountPermissions,
 		}); err != nil {
 			return makeError(fmt.Sprintf("Error creating account at address %s: %s",
 				evmCaller.String(), err.Error()))
@@ -76,17 +153,30 @@ func VmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryEx
 	// inputBytes is either a contract bytecode or an ABI-encoded function - a hex string
 	inputBytes := hex.MustDecodeString(C.GoString(input))
 
+	sink := vm.NewIrohaEventSink(worldState)
+	var privateState acmstate.ReaderWriter
+	if private {
+		privateState = vm.NewPrivateState(worldState)
+	}
 	engine := EngineWrapper{
-		engine:    burrowEVM,
-		state:     worldState,
-		eventSink: vm.NewIrohaEventSink(worldState),
+		engine:       burrowEVM,
+		state:        worldState,
+		privateState: privateState,
+		eventSink:    sink,
 	}
 
+	var calleeStr string
+	if callee != nil {
+		calleeStr = C.GoString(callee)
+	}
+	txHash := computeTxHash(evmCaller, calleeStr, inputBytes, C.GoString(nonce))
+
 	if callee == nil {
 		output, err := engine.NewContract(evmCaller, inputBytes, C.GoString(nonce))
 		if err != nil {
 			return makeError(err.Error())
 		}
+		persistLogIndex(sink, txHash, evmCaller.String(), "", output, privateState)
 		return C.CString(output), nil
 	}
 
@@ -105,6 +195,7 @@ func VmCall(input, caller, callee, nonce *C.const_char, commandExecutor, queryEx
 	if err != nil {
 		return makeError(err.Error())
 	}
+	persistLogIndex(sink, txHash, evmCaller.String(), evmCallee.String(), "", privateState)
 	if output == nil {
 		return nil, nil
 	}
@@ -116,22 +207,28 @@ func (w *EngineWrapper) NewContract(caller crypto.Address, code []byte, nonce st
 	var gas uint64 = 1000000
 
 	callee := addressFromNonce(nonce)
+	state := w.targetState()
+
+	var err error
+	defer snapshotGuard(state, &err)()
 
 	// Check if this address is, indeed, new and available
-	calleeAccount, err := w.state.GetAccount(callee)
+	var calleeAccount *acm.Account
+	calleeAccount, err = state.GetAccount(callee)
 	if err != nil {
 		return "", err
 	}
 	if calleeAccount != nil {
-		return "", fmt.Errorf("Account already exists at address %s", callee.String())
+		err = fmt.Errorf("Account already exists at address %s", callee.String())
+		return "", err
 	}
 
-	if err := w.state.UpdateAccount(&acm.Account{
+	if err = state.UpdateAccount(&acm.Account{
 		Address:     callee,
-		Permissions: permission.DefaultAccountPermissions,
+		Permissions: vm.DefaultAccountPermissions,
 	}); err != nil {
-		return "", fmt.Errorf("Error creating account at address %s: %s",
-			callee.String(), err.Error())
+		err = fmt.Errorf("Error creating account at address %s: %s", callee.String(), err.Error())
+		return "", err
 	}
 
 	params := engine.CallParams{
@@ -141,15 +238,27 @@ func (w *EngineWrapper) NewContract(caller crypto.Address, code []byte, nonce st
 		Value:  0,
 		Gas:    &gas,
 	}
-	output, err = w.engine.Execute(w.state, blockchain.New(), w.eventSink, params, code)
+	output, err = w.engine.Execute(state, blockchain.Instance(), w.eventSink, params, code)
 	if err != nil {
-		return "", fmt.Errorf("Error deploying smart contract at address %s: %s",
-			callee.String(), err.Error())
+		err = fmt.Errorf("Error deploying smart contract at address %s: %s", callee.String(), err.Error())
+		return "", err
+	}
+
+	if err = native.InitCode(state, callee, output); err != nil {
+		err = fmt.Errorf("Error initializing contract code at address %s: %s", callee.String(), err.Error())
+		return "", err
 	}
 
-	if err := native.InitCode(w.state, callee, output); err != nil {
-		return "", fmt.Errorf("Error initializing contract code at address %s: %s",
-			callee.String(), err.Error())
+	// Contract-metadata-hash indexing only applies to the real public
+	// IrohaState; a private contract's bytecode deliberately isn't
+	// associated with a public metadata hash, so w.state (not targetState())
+	// is checked here regardless of whether this call is private.
+	if irohaState, ok := w.state.(*vm.IrohaState); ok {
+		if metahash, err := metadataHashFromHex(compiler.MetadataHashFromBytecode(hex.EncodeToString(output))); err == nil {
+			if err := irohaState.SetContractMetadataHash(callee, metahash); err != nil {
+				fmt.Println("Error associating metadata hash with contract", callee.String(), ":", err.Error())
+			}
+		}
 	}
 
 	return callee.String(), nil
@@ -157,16 +266,44 @@ func (w *EngineWrapper) NewContract(caller crypto.Address, code []byte, nonce st
 
 func (w *EngineWrapper) Execute(caller, callee crypto.Address, input []byte) ([]byte, error) {
 	var gas uint64 = 1000000
+	state := w.targetState()
+
+	var err error
+	defer snapshotGuard(state, &err)()
 
-	calleeAccount, err := w.state.GetAccount(callee)
+	var calleeAccount *acm.Account
+	calleeAccount, err = state.GetAccount(callee)
 	if err != nil {
-		return nil, fmt.Errorf("Error getting account at address %s: %s",
-			callee.String(), err.Error())
+		err = fmt.Errorf("Error getting account at address %s: %s", callee.String(), err.Error())
+		return nil, err
 	}
 	if calleeAccount == nil {
 		return nil, fmt.Errorf("Contract account does not exists at address %s", callee.String())
 	}
 
+	code := calleeAccount.EVMCode
+	if isDelegationDesignator(code) {
+		target := delegationTarget(code)
+		if vm.IsNative(target.String()) {
+			// A delegation designator pointing at a native/reserved address is a
+			// no-op: there is no bytecode to run in the authority's storage context.
+			return nil, nil
+		}
+		targetAccount, err := state.GetAccount(target)
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving delegation target %s for %s: %s",
+				target.String(), callee.String(), err.Error())
+		}
+		if targetAccount == nil {
+			return nil, fmt.Errorf("Delegation target account does not exist at address %s", target.String())
+		}
+		if isDelegationDesignator(targetAccount.EVMCode) {
+			return nil, fmt.Errorf("Recursive delegation is not allowed: %s delegates to %s which is itself a delegation",
+				callee.String(), target.String())
+		}
+		code = targetAccount.EVMCode
+	}
+
 	params := engine.CallParams{
 		Caller: caller,
 		Callee: callee,
@@ -174,16 +311,66 @@ func (w *EngineWrapper) Execute(caller, callee crypto.Address, input []byte) ([]
 		Value:  0,
 		Gas:    &gas,
 	}
-	output, err := w.engine.Execute(w.state, blockchain.New(), w.eventSink, params, calleeAccount.EVMCode)
+	output, err := w.engine.Execute(state, blockchain.Instance(), w.eventSink, params, code)
 
 	if err != nil {
-		return nil, fmt.Errorf("Error calling smart contract at address %s: %s",
-			callee.String(), err.Error())
+		err = fmt.Errorf("Error calling smart contract at address %s: %s", callee.String(), err.Error())
+		return nil, err
 	}
 
 	return output, nil
 }
 
+// persistLogIndex indexes the logs and bloom accumulated by sink under the
+// current block height, plus a receipt for txHash so EthGetTransactionReceipt
+// can look this call up directly. It is best-effort: a failure here must not
+// fail a call that the EVM itself already executed successfully. privateState
+// is non-nil only for a VmCallPrivate call, in which case the receipt also
+// carries a PrivateStateRoot for the callee account (see vm.PrivateStateRoot)
+// so a recipient can check its own copy of the private state matches.
+func persistLogIndex(sink *vm.IrohaEventSink, txHash []byte, from, to, contractAddress string, privateState acmstate.ReaderWriter) {
+	height := blockchain.Instance().LastBlockHeight()
+	if err := vm.PersistBlockIndex(height, sink.Bloom(), sink.Logs()); err != nil {
+		fmt.Println("Error persisting EVM log index:", err.Error())
+	}
+	receipt := vm.TxReceipt{
+		BlockHeight:     height,
+		Status:          1,
+		From:            from,
+		To:              to,
+		ContractAddress: contractAddress,
+		Logs:            sink.Logs(),
+	}
+	if privateState != nil {
+		callee := contractAddress
+		if callee == "" {
+			callee = to
+		}
+		if calleeAddress, err := crypto.AddressFromHexString(callee); err == nil {
+			if root, err := vm.PrivateStateRoot(privateState, calleeAddress, nil); err == nil {
+				receipt.PrivateStateRoot = hex.EncodeToString(root)
+			} else {
+				fmt.Println("Error computing private state root:", err.Error())
+			}
+		}
+	}
+	if err := vm.PersistTxReceipt(txHash, receipt); err != nil {
+		fmt.Println("Error persisting EVM tx receipt:", err.Error())
+	}
+}
+
+// computeTxHash derives this bridge's synthetic transaction hash: Iroha
+// transactions have no Ethereum-style hash of their own, so VmCall's inputs
+// (caller, callee, calldata and the creation nonce) are hashed together to
+// give EthGetTransactionReceipt something stable to look receipts up by.
+func computeTxHash(caller crypto.Address, callee string, input []byte, nonce string) []byte {
+	data := append([]byte{}, caller.Bytes()...)
+	data = append(data, []byte(callee)...)
+	data = append(data, input...)
+	data = append(data, []byte(nonce)...)
+	return crypto.Keccak256(data)
+}
+
 func makeError(msg string) (*C.char, *C.char) {
 	return nil, C.CString(msg)
 }
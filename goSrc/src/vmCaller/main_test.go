@@ -29,6 +29,35 @@ func (e *engineFailure) Execute(st acmstate.ReaderWriter, blockchain engine.Bloc
 	return nil, errors.New("Error executing contract")
 }
 
+// snapshottingState records Snapshot/RevertToSnapshot calls so tests can
+// assert NewContract/Execute's snapshotGuard calls RevertToSnapshot exactly
+// when the call fails, and not when it succeeds; it does not actually undo
+// any writes itself, the way the real iroha.IrohaStorage.Snapshot/
+// RevertToSnapshot pair will once the native side backs them with a real
+// shadow write-set (see iroha/storage.go's doc comment on Snapshot) - there
+// is no irohad/ C++ tree in this checkout to exercise that against.
+type snapshottingState struct {
+	acmstate.ReaderWriter
+	snapshots int
+	reverts   []uint64
+	commits   []uint64
+}
+
+func (s *snapshottingState) Snapshot() (uint64, error) {
+	s.snapshots++
+	return uint64(s.snapshots), nil
+}
+
+func (s *snapshottingState) RevertToSnapshot(id uint64) error {
+	s.reverts = append(s.reverts, id)
+	return nil
+}
+
+func (s *snapshottingState) CommitSnapshot(id uint64) error {
+	s.commits = append(s.commits, id)
+	return nil
+}
+
 type VmCallerTestSuite struct {
 	suite.Suite
 
@@ -89,6 +118,38 @@ func (s *VmCallerTestSuite) TestCheck() {
 	s.Require().Equal("Error calling smart contract at address D9EB767B19A58B514765B844D0BCF0CD221660AC: Error executing contract", err.Error())
 }
 
+// TestSnapshotRevert checks that NewContract/Execute revert the snapshot
+// taken at entry when, and only when, the call returns an error - a
+// snapshotter state that never errors should never see RevertToSnapshot.
+func (s *VmCallerTestSuite) TestSnapshotRevert() {
+	snap := &snapshottingState{ReaderWriter: acmstate.NewMemoryState()}
+	engineOk := &EngineWrapper{
+		engine:    &engineSuccess{output: []byte("01")},
+		state:     snap,
+		eventSink: s.eventSink,
+	}
+	engineErr := &EngineWrapper{
+		engine:    &engineFailure{},
+		state:     snap,
+		eventSink: s.eventSink,
+	}
+
+	caller := crypto.MustAddressFromHexString("0123456789ABCDEF0123456789ABCDEF01234567")
+	code := hex.MustDecodeString("C0DE")
+	input := hex.MustDecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	nonce := "41424344454647"
+
+	callee, err := engineOk.NewContract(caller, code, nonce)
+	s.Require().NoError(err)
+	s.Require().Empty(snap.reverts, "a successful NewContract must not revert its snapshot")
+	s.Require().Len(snap.commits, 1, "a successful NewContract must commit its snapshot")
+
+	_, err = engineErr.Execute(caller, crypto.MustAddressFromHexString(callee), input)
+	s.Require().Error(err)
+	s.Require().Len(snap.reverts, 1, "a failed Execute must revert the snapshot it took")
+	s.Require().Len(snap.commits, 1, "a failed Execute must not also commit its snapshot")
+}
+
 func TestVmCallerTestSuite(t *testing.T) {
 	suite.Run(t, new(VmCallerTestSuite))
 }
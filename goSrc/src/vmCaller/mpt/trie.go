@@ -0,0 +1,267 @@
+// Package mpt builds ephemeral, in-memory Merkle-Patricia tries, the way
+// EthGetProof needs to produce EIP-1186-shaped proofs even though Iroha's
+// own storage isn't trie-shaped: a fresh trie is built per request from
+// just the handful of keys being proved, rather than maintained
+// persistently across the chain's lifetime.
+package mpt
+
+import (
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// Node is one node of a Merkle-Patricia trie. Only this package's own types
+// implement it.
+type Node interface {
+	isNode()
+}
+
+type leafNode struct {
+	key   []byte // remaining nibbles
+	value []byte
+}
+
+type extensionNode struct {
+	key   []byte // shared nibbles
+	child Node
+}
+
+type branchNode struct {
+	children [16]Node
+	value    []byte // set when a key ends exactly at this branch
+}
+
+func (*leafNode) isNode()      {}
+func (*extensionNode) isNode() {}
+func (*branchNode) isNode()    {}
+
+// Insert returns the trie resulting from inserting key/value into root (nil
+// for an empty trie).
+func Insert(root Node, key, value []byte) Node {
+	return insert(root, bytesToNibbles(key), value)
+}
+
+// RootHash returns the Keccak-256 hash a verifier checks an EIP-1186 proof
+// against: keccak256(RLP("")) for an empty trie, keccak256(RLP(root))
+// otherwise.
+func RootHash(root Node) []byte {
+	if root == nil {
+		return crypto.Keccak256(rlpBytes(nil))
+	}
+	return crypto.Keccak256(rlpEncodeNode(root))
+}
+
+// Prove returns the RLP-encoded nodes visited from root down to the leaf
+// storing key, the proof array an EIP-1186 verifier replays against
+// RootHash(root), or false if no value is stored under key.
+func Prove(root Node, key []byte) ([][]byte, bool) {
+	return proveKey(root, bytesToNibbles(key))
+}
+
+func commonPrefixLen(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func insert(n Node, key, value []byte) Node {
+	switch cur := n.(type) {
+	case nil:
+		return &leafNode{key: key, value: value}
+	case *leafNode:
+		return insertAtLeaf(cur, key, value)
+	case *extensionNode:
+		return insertAtExtension(cur, key, value)
+	case *branchNode:
+		return insertAtBranch(cur, key, value)
+	default:
+		return n
+	}
+}
+
+func insertAtLeaf(cur *leafNode, key, value []byte) Node {
+	cp := commonPrefixLen(cur.key, key)
+	if cp == len(cur.key) && cp == len(key) {
+		return &leafNode{key: key, value: value}
+	}
+
+	br := &branchNode{}
+	if cp == len(cur.key) {
+		br.value = cur.value
+	} else {
+		br.children[cur.key[cp]] = &leafNode{key: cur.key[cp+1:], value: cur.value}
+	}
+	if cp == len(key) {
+		br.value = value
+	} else {
+		br.children[key[cp]] = &leafNode{key: key[cp+1:], value: value}
+	}
+
+	var result Node = br
+	if cp > 0 {
+		result = &extensionNode{key: key[:cp], child: br}
+	}
+	return result
+}
+
+func insertAtExtension(cur *extensionNode, key, value []byte) Node {
+	cp := commonPrefixLen(cur.key, key)
+	if cp == len(cur.key) {
+		return &extensionNode{key: cur.key, child: insert(cur.child, key[cp:], value)}
+	}
+
+	br := &branchNode{}
+	afterDivergence := cur.child
+	if cp+1 < len(cur.key) {
+		afterDivergence = &extensionNode{key: cur.key[cp+1:], child: cur.child}
+	}
+	br.children[cur.key[cp]] = afterDivergence
+
+	if cp == len(key) {
+		br.value = value
+	} else {
+		br.children[key[cp]] = &leafNode{key: key[cp+1:], value: value}
+	}
+
+	var result Node = br
+	if cp > 0 {
+		result = &extensionNode{key: key[:cp], child: br}
+	}
+	return result
+}
+
+func insertAtBranch(cur *branchNode, key, value []byte) Node {
+	if len(key) == 0 {
+		cur.value = value
+		return cur
+	}
+	idx := key[0]
+	cur.children[idx] = insert(cur.children[idx], key[1:], value)
+	return cur
+}
+
+func proveKey(n Node, key []byte) ([][]byte, bool) {
+	switch v := n.(type) {
+	case nil:
+		return nil, false
+	case *leafNode:
+		if len(key) != len(v.key) || commonPrefixLen(v.key, key) != len(v.key) {
+			return nil, false
+		}
+		return [][]byte{rlpEncodeNode(v)}, true
+	case *extensionNode:
+		if len(key) < len(v.key) || commonPrefixLen(v.key, key) != len(v.key) {
+			return nil, false
+		}
+		rest, ok := proveKey(v.child, key[len(v.key):])
+		if !ok {
+			return nil, false
+		}
+		return append([][]byte{rlpEncodeNode(v)}, rest...), true
+	case *branchNode:
+		if len(key) == 0 {
+			if v.value == nil {
+				return nil, false
+			}
+			return [][]byte{rlpEncodeNode(v)}, true
+		}
+		rest, ok := proveKey(v.children[key[0]], key[1:])
+		if !ok {
+			return nil, false
+		}
+		return append([][]byte{rlpEncodeNode(v)}, rest...), true
+	default:
+		return nil, false
+	}
+}
+
+func bytesToNibbles(b []byte) []byte {
+	nibbles := make([]byte, len(b)*2)
+	for i, c := range b {
+		nibbles[2*i] = c >> 4
+		nibbles[2*i+1] = c & 0x0f
+	}
+	return nibbles
+}
+
+// hexPrefix applies Ethereum's hex-prefix compact encoding to a leaf's or
+// extension's nibble path: a flag nibble (2 for a leaf, 0 for an extension,
+// +1 if the path has an odd number of nibbles) followed by the path itself,
+// padded to a whole number of bytes.
+func hexPrefix(nibbles []byte, isLeaf bool) []byte {
+	flag := byte(0)
+	if isLeaf {
+		flag = 2
+	}
+	var withFlag []byte
+	if len(nibbles)%2 == 1 {
+		withFlag = append([]byte{flag + 1}, nibbles...)
+	} else {
+		withFlag = append([]byte{flag, 0}, nibbles...)
+	}
+	packed := make([]byte, len(withFlag)/2)
+	for i := range packed {
+		packed[i] = withFlag[2*i]<<4 | withFlag[2*i+1]
+	}
+	return packed
+}
+
+func rlpEncodeNode(n Node) []byte {
+	switch v := n.(type) {
+	case *leafNode:
+		return rlpList(rlpBytes(hexPrefix(v.key, true)), rlpBytes(v.value))
+	case *extensionNode:
+		return rlpList(rlpBytes(hexPrefix(v.key, false)), childRef(v.child))
+	case *branchNode:
+		items := make([][]byte, 17)
+		for i := 0; i < 16; i++ {
+			items[i] = childRef(v.children[i])
+		}
+		items[16] = rlpBytes(v.value)
+		return rlpList(items...)
+	default:
+		return rlpBytes(nil)
+	}
+}
+
+// childRef is how a parent node references a child: the child's own
+// RLP encoding, embedded directly if it's under 32 bytes, otherwise the
+// encoding's Keccak-256 hash — the same space-saving rule Ethereum's trie
+// uses to avoid hashing tiny subtrees.
+func childRef(n Node) []byte {
+	if n == nil {
+		return rlpBytes(nil)
+	}
+	encoded := rlpEncodeNode(n)
+	if len(encoded) < 32 {
+		return encoded
+	}
+	return rlpBytes(crypto.Keccak256(encoded))
+}
+
+func rlpBytes(data []byte) []byte {
+	if len(data) == 1 && data[0] < 0x80 {
+		return data
+	}
+	return append(rlpLength(len(data), 0x80, 0xb7), data...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var body []byte
+	for _, item := range items {
+		body = append(body, item...)
+	}
+	return append(rlpLength(len(body), 0xc0, 0xf7), body...)
+}
+
+func rlpLength(length, shortBase, longBase int) []byte {
+	if length < 56 {
+		return []byte{byte(shortBase + length)}
+	}
+	var lengthBytes []byte
+	for n := length; n > 0; n >>= 8 {
+		lengthBytes = append([]byte{byte(n)}, lengthBytes...)
+	}
+	return append([]byte{byte(longBase + len(lengthBytes))}, lengthBytes...)
+}
@@ -0,0 +1,66 @@
+package mpt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInsertAndProveSingleKey(t *testing.T) {
+	var root Node
+	root = Insert(root, []byte("key"), []byte("value"))
+
+	proof, ok := Prove(root, []byte("key"))
+	if !ok {
+		t.Fatal("expected Prove to find the key just inserted")
+	}
+	if len(proof) == 0 {
+		t.Fatal("expected a non-empty proof")
+	}
+	if got := RootHash(root); len(got) != 32 {
+		t.Fatalf("RootHash returned %d bytes, want 32", len(got))
+	}
+}
+
+func TestProveMissingKey(t *testing.T) {
+	var root Node
+	root = Insert(root, []byte("key"), []byte("value"))
+
+	if _, ok := Prove(root, []byte("other")); ok {
+		t.Fatal("expected Prove to report false for a key that was never inserted")
+	}
+}
+
+func TestInsertManyKeysAllProvable(t *testing.T) {
+	var root Node
+	entries := map[string]string{
+		"alpha":    "1",
+		"alphabet": "2",
+		"beta":     "3",
+		"":         "4",
+	}
+	for k, v := range entries {
+		root = Insert(root, []byte(k), []byte(v))
+	}
+
+	for k := range entries {
+		if _, ok := Prove(root, []byte(k)); !ok {
+			t.Fatalf("expected Prove to find key %q after inserting every entry", k)
+		}
+	}
+}
+
+func TestRootHashChangesWithContent(t *testing.T) {
+	var a, b Node
+	a = Insert(a, []byte("key"), []byte("value"))
+	b = Insert(b, []byte("key"), []byte("other-value"))
+
+	if bytes.Equal(RootHash(a), RootHash(b)) {
+		t.Fatal("expected different values under the same key to produce different root hashes")
+	}
+
+	var c Node
+	c = Insert(c, []byte("key"), []byte("value"))
+	if !bytes.Equal(RootHash(a), RootHash(c)) {
+		t.Fatal("expected identical tries to produce identical root hashes")
+	}
+}
@@ -0,0 +1,583 @@
+// Package eth implements the eth_* JSON-RPC namespace (PublicEthereumAPI)
+// and, in filter.go, the filter-subsystem namespace (PublicFilterAPI) that
+// backs eth_newFilter/eth_getLogs/etc. Both were split out of the single
+// god-object rpc.EthService used to implement directly.
+package eth
+
+import (
+	"strconv"
+	"strings"
+
+	vm "vmCaller/evm"
+	myExecution "vmCaller/execution"
+	"vmCaller/rpc/namespaces"
+	"vmCaller/rpc/namespaces/personal"
+
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/acm/validator"
+	bcm "github.com/hyperledger/burrow/bcm"
+	"github.com/hyperledger/burrow/crypto"
+	x "github.com/hyperledger/burrow/encoding/hex"
+	"github.com/hyperledger/burrow/execution"
+	"github.com/hyperledger/burrow/execution/exec"
+	"github.com/hyperledger/burrow/execution/state"
+	"github.com/hyperledger/burrow/logging"
+	"github.com/hyperledger/burrow/rpc/web3"
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/txs/payload"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	ChainID     = 1
+	hexZero     = "0x0"
+	hexZeroNonce = "0x0000000000000000"
+
+	// maxGasLimit bounds both EthCall's simulated execution and the top of
+	// EthEstimateGas's binary search. This bridge has no notion of a real
+	// per-block gas limit (VmCall's own EngineWrapper.Execute hard-codes
+	// 1,000,000 gas per call; see main.go), so this is a standalone ceiling
+	// rather than a value read from any block.
+	maxGasLimit = uint64(10000000)
+
+	// gasEstimateThreshold is how close EthEstimateGas's binary search needs
+	// to narrow lo/hi before it stops and returns hi, per the request this
+	// implements it against.
+	gasEstimateThreshold = uint64(1000)
+)
+
+// EventsReader is the subset of execution/state.State this namespace reads
+// committed transaction execution events through.
+type EventsReader interface {
+	TxsAtHeight(height uint64) ([]*exec.TxExecution, error)
+	TxByHash(txHash []byte) (*exec.TxExecution, error)
+}
+
+var _ EventsReader = &state.State{}
+
+// AccountsSigner is the subset of the personal_* namespace's
+// PrivateAccountAPI that EthAccounts/EthSign/EthSendTransaction need:
+// enough to list known addresses and sign with one that's currently
+// unlocked via personal_unlockAccount, without this package importing
+// personal's whole RPC surface.
+type AccountsSigner interface {
+	PersonalListAccounts() (*personal.PersonalListAccountsResult, error)
+	IsUnlocked(address crypto.Address) bool
+	SignUnlocked(address crypto.Address, digest []byte) (crypto.Signature, error)
+}
+
+// PublicEthereumAPI implements the eth_* namespace other than the filter
+// subsystem (see PublicFilterAPI).
+type PublicEthereumAPI struct {
+	Accounts   acmstate.IterableStatsReader
+	Events     EventsReader
+	Blockchain bcm.BlockchainInfo
+	Validators validator.History
+	Trans      *execution.Transactor
+	Signer     AccountsSigner
+	Logger     *logging.Logger
+	Namespaces *namespaces.Set
+}
+
+// NewPublicEthereumAPI builds the eth_* namespace's API.
+func NewPublicEthereumAPI(accounts acmstate.IterableStatsReader, events EventsReader,
+	blockchain bcm.BlockchainInfo, validators validator.History, trans *execution.Transactor,
+	signer AccountsSigner, logger *logging.Logger, ns *namespaces.Set) *PublicEthereumAPI {
+
+	return &PublicEthereumAPI{
+		Accounts:   accounts,
+		Events:     events,
+		Blockchain: blockchain,
+		Validators: validators,
+		Trans:      trans,
+		Signer:     signer,
+		Logger:     logger,
+		Namespaces: ns,
+	}
+}
+
+func (api *PublicEthereumAPI) enabled() error {
+	if !api.Namespaces.Enabled("eth") {
+		return web3.ErrNotFound
+	}
+	return nil
+}
+
+func (api *PublicEthereumAPI) EthProtocolVersion() (*web3.EthProtocolVersionResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthChainId() (*web3.EthChainIdResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.EthChainIdResult{
+		ChainID: x.EncodeNumber(uint64(ChainID)),
+	}, nil
+}
+
+func (api *PublicEthereumAPI) EthBlockNumber() (*web3.EthBlockNumberResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+// EthCall executes a new message call immediately without creating a transaction
+func (api *PublicEthereumAPI) EthCall(req *web3.EthCallParams) (*web3.EthCallResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	var to crypto.Address
+	var from string
+	var err error
+
+	if addr := req.Transaction.To; addr != "" {
+		to, err = x.DecodeToAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if addr := req.Transaction.From; addr != "" {
+		from = addr
+	}
+
+	data, err := x.DecodeToBytes(req.Transaction.Data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeValue(req.Transaction.Value)
+	if err != nil {
+		return nil, err
+	}
+	txe, _, err := myExecution.CallSim(api.Accounts, api.Blockchain, from, to, data, value, maxGasLimit, api.Logger)
+	if err != nil {
+		return nil, err
+	} else if txe.Exception != nil {
+		return nil, txe.Exception.AsError()
+	}
+
+	var result string
+	if r := txe.GetResult(); r != nil {
+		result = x.EncodeBytes(r.GetReturn())
+	}
+
+	return &web3.EthCallResult{
+		ReturnValue: result,
+	}, nil
+}
+
+func (api *PublicEthereumAPI) EthGetBalance(req *web3.EthGetBalanceParams) (*web3.EthGetBalanceResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetBlockByHash(req *web3.EthGetBlockByHashParams) (*web3.EthGetBlockByHashResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetBlockByNumber(req *web3.EthGetBlockByNumberParams) (*web3.EthGetBlockByNumberResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetBlockTransactionCountByHash(req *web3.EthGetBlockTransactionCountByHashParams) (*web3.EthGetBlockTransactionCountByHashResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetBlockTransactionCountByNumber(req *web3.EthGetBlockTransactionCountByNumberParams) (*web3.EthGetBlockTransactionCountByNumberResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetCode(req *web3.EthGetCodeParams) (*web3.EthGetCodeResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetStorageAt(req *web3.EthGetStorageAtParams) (*web3.EthGetStorageAtResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetTransactionByBlockHashAndIndex(req *web3.EthGetTransactionByBlockHashAndIndexParams) (*web3.EthGetTransactionByBlockHashAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetTransactionByBlockNumberAndIndex(req *web3.EthGetTransactionByBlockNumberAndIndexParams) (*web3.EthGetTransactionByBlockNumberAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetTransactionByHash(req *web3.EthGetTransactionByHashParams) (*web3.EthGetTransactionByHashResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetTransactionCount(req *web3.EthGetTransactionCountParams) (*web3.EthGetTransactionCountResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func getHashAndCallTxFromEnvelope(env *txs.Envelope) ([]byte, *payload.CallTx, error) {
+	return nil, nil, web3.ErrNotFound
+}
+
+// getHashAndCallTxFromExecution stays unfilled: it would decode a CallTx out
+// of a committed exec.TxExecution, but txe is only ever produced by burrow's
+// own execution/state.State pipeline, which this bridge never populates (see
+// api.Events' doc comment) — VmCall executes the EVM directly via cgo and
+// never builds or commits a txs.Envelope. EthGetTransactionReceipt instead
+// looks receipts up through vm.LoadTxReceipt, the index main.go's
+// persistLogIndex writes to for every VmCall.
+func getHashAndCallTxFromExecution(txe *exec.TxExecution) ([]byte, *payload.CallTx, error) {
+	return nil, nil, web3.ErrNotFound
+}
+
+// EthGetTransactionReceipt composes a receipt from vm.LoadTxReceipt's index
+// rather than from api.Events (see getHashAndCallTxFromExecution). Two
+// fields are necessarily approximate: CumulativeGasUsed/GasUsed are always
+// hexZero, since EngineWrapper.Execute doesn't report gas consumption back
+// out of the EVM yet, and Logs is left unset because web3's log-entry type
+// isn't vendored in this tree to guess at safely.
+func (api *PublicEthereumAPI) EthGetTransactionReceipt(req *web3.EthGetTransactionReceiptParams) (*web3.EthGetTransactionReceiptResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	txHash, err := x.DecodeToBytes(req.TransactionHash)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := vm.LoadTxReceipt(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, web3.ErrNotFound
+	}
+
+	header, err := api.getBlockHeaderAtHeight(receipt.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var bloom vm.Bloom
+	for _, log := range receipt.Logs {
+		logBloom, err := x.DecodeToBytes(log.Bloom)
+		if err != nil {
+			return nil, err
+		}
+		var b vm.Bloom
+		copy(b[:], logBloom)
+		bloom.Merge(b)
+	}
+
+	return &web3.EthGetTransactionReceiptResult{
+		TransactionHash:   req.TransactionHash,
+		TransactionIndex:  x.EncodeNumber(receipt.TxIndex),
+		BlockHash:         header.Hash().String(),
+		BlockNumber:       x.EncodeNumber(receipt.BlockHeight),
+		From:              receipt.From,
+		To:                receipt.To,
+		CumulativeGasUsed: hexZero,
+		GasUsed:           hexZero,
+		ContractAddress:   receipt.ContractAddress,
+		LogsBloom:         x.EncodeBytes(bloom[:]),
+		Status:            x.EncodeNumber(receipt.Status),
+	}, nil
+}
+
+func (api *PublicEthereumAPI) EthHashrate() (*web3.EthHashrateResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthMining() (*web3.EthMiningResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthPendingTransactions() (*web3.EthPendingTransactionsResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+// EthEstimateGas finds the least gas req.Transaction can run with by running
+// it once at maxGasLimit to confirm it succeeds at all and to get a used-gas
+// lower bound, then binary-searching the remaining range: lo starts at that
+// used-gas figure, hi at 2x it (capped at maxGasLimit), and the search stops
+// once hi-lo is within gasEstimateThreshold and returns hi, the lowest limit
+// seen to succeed. Only limits at or below lo are known to run out of gas
+// (see myExecution.IsOutOfGas); limits between lo and hi are not tried.
+func (api *PublicEthereumAPI) EthEstimateGas(req *web3.EthEstimateGasParams) (*web3.EthEstimateGasResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	var to crypto.Address
+	var from string
+	var err error
+
+	if addr := req.Transaction.To; addr != "" {
+		to, err = x.DecodeToAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if addr := req.Transaction.From; addr != "" {
+		from = addr
+	}
+
+	data, err := x.DecodeToBytes(req.Transaction.Data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeValue(req.Transaction.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	run := func(gasLimit uint64) (*exec.TxExecution, uint64, error) {
+		return myExecution.CallSim(api.Accounts, api.Blockchain, from, to, data, value, gasLimit, api.Logger)
+	}
+
+	txe, used, err := run(maxGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	if txe.Exception != nil {
+		return nil, txe.Exception.AsError()
+	}
+
+	lo, hi := used, used*2
+	if hi > maxGasLimit {
+		hi = maxGasLimit
+	}
+	for hi-lo > gasEstimateThreshold {
+		mid := lo + (hi-lo)/2
+		midTxe, _, err := run(mid)
+		if err != nil {
+			return nil, err
+		}
+		if midTxe.Exception != nil {
+			if !myExecution.IsOutOfGas(midTxe) {
+				return nil, midTxe.Exception.AsError()
+			}
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	// hi is only known to succeed when the loop body actually narrowed it
+	// down to mid (hi starts at 2x the maxGasLimit run's used-gas figure,
+	// which was never itself run at exactly hi). Confirm it here the same
+	// way go-ethereum's own doEstimateGas does, rather than return an
+	// unverified upper bound.
+	if hi != maxGasLimit {
+		hiTxe, _, err := run(hi)
+		if err != nil {
+			return nil, err
+		}
+		if hiTxe.Exception != nil {
+			return nil, hiTxe.Exception.AsError()
+		}
+	}
+
+	return &web3.EthEstimateGasResult{
+		GasUsed: x.EncodeNumber(hi),
+	}, nil
+}
+
+func (api *PublicEthereumAPI) EthGasPrice() (*web3.EthGasPriceResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.EthGasPriceResult{
+		GasPrice: hexZero,
+	}, nil
+}
+
+func (api *PublicEthereumAPI) EthGetRawTransactionByHash(req *web3.EthGetRawTransactionByHashParams) (*web3.EthGetRawTransactionByHashResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetRawTransactionByBlockHashAndIndex(req *web3.EthGetRawTransactionByBlockHashAndIndexParams) (*web3.EthGetRawTransactionByBlockHashAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetRawTransactionByBlockNumberAndIndex(req *web3.EthGetRawTransactionByBlockNumberAndIndexParams) (*web3.EthGetRawTransactionByBlockNumberAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+// EthSendRawTransaction decodes and validates the raw transaction but
+// cannot submit it yet; see rawtx.go's errSenderRecoveryUnsupported.
+func (api *PublicEthereumAPI) EthSendRawTransaction(req *web3.EthSendRawTransactionParams) (*web3.EthSendRawTransactionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	raw, err := x.DecodeToBytes(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	return api.sendRawTransaction(raw)
+}
+
+// EthSendPrivateRawTransactionParams is eth_sendPrivateRawTransaction's
+// Quorum-style request: the same raw, signed transaction
+// EthSendRawTransaction accepts, plus PrivateFor naming the recipients it
+// should be routed to as a private (VmCallPrivate) call instead of a public
+// one. There is no vendored web3.EthSendPrivateRawTransactionParams to
+// match, since this isn't a standard Ethereum JSON-RPC method, so the
+// request/result types are defined locally here the same way
+// personal.PersonalSendTransactionParams is.
+type EthSendPrivateRawTransactionParams struct {
+	Data       string
+	PrivateFor []string
+}
+
+type EthSendPrivateRawTransactionResult struct {
+	TransactionHash string
+}
+
+// EthSendPrivateRawTransaction is EthSendRawTransaction's private-transaction
+// sibling (see main.go's VmCallPrivate): it shares the exact same
+// sender-recovery gap as EthSendRawTransaction (see rawtx.go's
+// recoverSender), so it is deferred here rather than guessed at.
+func (api *PublicEthereumAPI) EthSendPrivateRawTransaction(req *EthSendPrivateRawTransactionParams) (*EthSendPrivateRawTransactionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthSyncing() (*web3.EthSyncingResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) getBlockHeightByHash(hash string) (uint64, error) {
+	return 0, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) getBlockHeaderAtHeight(height uint64) (*types.Header, error) {
+	return api.Blockchain.GetBlockHeader(height)
+}
+
+func hexKeccak(data []byte) string {
+	return x.EncodeBytes(crypto.Keccak256(data))
+}
+
+// decodeValue decodes the 0x-prefixed hex wei amount EthCallParams and
+// EthEstimateGasParams carry as Transaction.Value, or returns 0 for a call
+// that carries no value at all.
+func decodeValue(hexValue string) (uint64, error) {
+	if hexValue == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(strings.TrimPrefix(hexValue, "0x"), 16, 64)
+}
+
+func hexKeccakAddress(data []byte) string {
+	addr := crypto.Keccak256(data)
+	return x.EncodeBytes(addr[len(addr)-20:])
+}
+
+func (api *PublicEthereumAPI) getBlockInfoAtHeight(height uint64, includeTxs bool) (web3.Block, error) {
+	return web3.Block{}, web3.ErrNotFound
+}
+
+func getTransaction(block *types.Header, hash []byte, tx *payload.CallTx) web3.Transaction {
+	return web3.Transaction{}
+}
+
+func (api *PublicEthereumAPI) getHeightByWord(height string) (uint64, bool) {
+	switch height {
+	case "earliest":
+		return 0, true
+	case "latest", "pending":
+		return api.Blockchain.LastBlockHeight(), true
+		// TODO: pending state/transactions
+	default:
+		return 0, false
+	}
+}
+
+func getHeightByNumber(height string) (uint64, error) {
+	return 0, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) getHeightByWordOrNumber(i string) (uint64, error) {
+	return 0, web3.ErrNotFound
+}
+
+// EthSendTransaction is meant to sign req with the unlocked req.From key
+// (via api.Signer) and broadcast it through api.Trans, the same gap
+// personal.PrivateAccountAPI.PersonalSendTransaction documents: there's no
+// vendored execution.Transactor in this tree to confirm its broadcast
+// entry point's exact signature against.
+func (api *PublicEthereumAPI) EthSendTransaction(req *web3.EthSendTransactionParams) (*web3.EthSendTransactionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return nil, web3.ErrNotFound
+}
+
+// EthAccounts returns all accounts signable from the local node
+func (api *PublicEthereumAPI) EthAccounts() (*web3.EthAccountsResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	accounts, err := api.Signer.PersonalListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return &web3.EthAccountsResult{Accounts: accounts.Accounts}, nil
+}
+
+// EthSign produces an eth_sign signature over req.Data: the Ethereum
+// signed-message digest (see personal.EthereumSignedMessageHash) signed by
+// req.Address's key, which must already be unlocked via
+// personal_unlockAccount.
+func (api *PublicEthereumAPI) EthSign(req *web3.EthSignParams) (*web3.EthSignResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	address, err := x.DecodeToAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	message, err := x.DecodeToBytes(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := api.Signer.SignUnlocked(address, personal.EthereumSignedMessageHash(message))
+	if err != nil {
+		return nil, err
+	}
+	return &web3.EthSignResult{Signature: x.EncodeBytes(sig.Bytes())}, nil
+}
+
+func (api *PublicEthereumAPI) EthSubmitHashrate(req *web3.EthSubmitHashrateParams) (*web3.EthSubmitHashrateResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthSubmitWork(*web3.EthSubmitWorkParams) (*web3.EthSubmitWorkResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetUncleByBlockHashAndIndex(req *web3.EthGetUncleByBlockHashAndIndexParams) (*web3.EthGetUncleByBlockHashAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetUncleByBlockNumberAndIndex(req *web3.EthGetUncleByBlockNumberAndIndexParams) (*web3.EthGetUncleByBlockNumberAndIndexResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetUncleCountByBlockHash(req *web3.EthGetUncleCountByBlockHashParams) (*web3.EthGetUncleCountByBlockHashResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthGetUncleCountByBlockNumber(req *web3.EthGetUncleCountByBlockNumberParams) (*web3.EthGetUncleCountByBlockNumberResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+// EthGetProof is implemented in proof.go.
+
+func (api *PublicEthereumAPI) EthGetWork() (*web3.EthGetWorkResult, error) {
+	return nil, web3.ErrNotFound
+}
+
+func (api *PublicEthereumAPI) EthCoinbase() (*web3.EthCoinbaseResult, error) {
+	return nil, web3.ErrNotFound
+}
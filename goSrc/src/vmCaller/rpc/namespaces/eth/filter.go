@@ -0,0 +1,346 @@
+package eth
+
+import (
+	"strconv"
+	"strings"
+
+	vm "vmCaller/evm"
+	"vmCaller/rpc/namespaces"
+
+	bcm "github.com/hyperledger/burrow/bcm"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	x "github.com/hyperledger/burrow/encoding/hex"
+	"github.com/hyperledger/burrow/logging"
+	"github.com/hyperledger/burrow/rpc/web3"
+)
+
+// PublicFilterAPI implements the filter subsystem backing
+// eth_newFilter/eth_getFilterChanges/eth_getLogs/eth_newBlockFilter, on top
+// of the FilterManager that tracks open filters and the block-bloom index
+// chunk2-5 built.
+//
+// EthGetFilterChanges/EthGetFilterLogs/EthGetLogs drive real matching
+// through LogsFilter and marshal the result into
+// web3.EthGetFilterChangesResult/EthGetFilterLogsResult/EthGetLogsResult —
+// this bridge's best-effort guess at those types' field layout (see
+// web3LogFromEvent below), since rpc/web3 isn't vendored in this tree to
+// confirm it against, following the same single-field wrapper-struct
+// convention this file already uses for EthNewFilterResult/
+// EthUninstallFilterResult. The log objects themselves carry
+// Address/Data/Topics/BlockNumber, the fields vm.LoggedEvent (see
+// evm/log_index.go) actually stores; TransactionHash/TransactionIndex/
+// BlockHash/LogIndex are left at their zero value because LoggedEvent never
+// recorded which transaction or position produced a log — widening that
+// stored shape is a separate, larger change than this one.
+type PublicFilterAPI struct {
+	Blockchain bcm.BlockchainInfo
+	Events     EventsReader
+	Logger     *logging.Logger
+	Namespaces *namespaces.Set
+	filters    *FilterManager
+}
+
+// NewPublicFilterAPI builds the filter-subsystem namespace's API.
+func NewPublicFilterAPI(blockchain bcm.BlockchainInfo, events EventsReader,
+	logger *logging.Logger, ns *namespaces.Set) *PublicFilterAPI {
+
+	return &PublicFilterAPI{
+		Blockchain: blockchain,
+		Events:     events,
+		Logger:     logger,
+		Namespaces: ns,
+		filters:    newFilterManager(blockchain),
+	}
+}
+
+func (api *PublicFilterAPI) enabled() error {
+	if !api.Namespaces.Enabled("filter") {
+		return web3.ErrNotFound
+	}
+	return nil
+}
+
+func (api *PublicFilterAPI) EthUninstallFilter(req *web3.EthUninstallFilterParams) (*web3.EthUninstallFilterResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.EthUninstallFilterResult{IsUninstalled: api.filters.remove(req.FilterId)}, nil
+}
+
+func (api *PublicFilterAPI) EthNewBlockFilter() (*web3.EthNewBlockFilterResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	id := api.filters.add(&filterState{kind: filterKindBlock})
+	return &web3.EthNewBlockFilterResult{FilterId: id}, nil
+}
+
+// EthNewFilter allocates a log filter over the given block range, address
+// set and topic pattern. req.Filter's field names below are this bridge's
+// best-effort guess at web3.EthNewFilterParams' shape, following the
+// req.Transaction nesting convention EthCall already established for this
+// package — adjust decodeFilterCriteria/parseBlockParam if rpc/web3's real
+// shape differs.
+func (api *PublicFilterAPI) EthNewFilter(req *web3.EthNewFilterParams) (*web3.EthNewFilterResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	fromBlock, err := api.parseBlockParam(req.Filter.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, topics, err := decodeFilterCriteria(req.Filter.Address, req.Filter.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastPolled uint64
+	if fromBlock > 0 {
+		lastPolled = fromBlock - 1
+	}
+
+	id := api.filters.add(&filterState{
+		kind:       filterKindLog,
+		addresses:  addresses,
+		topics:     topics,
+		lastPolled: lastPolled,
+	})
+	return &web3.EthNewFilterResult{FilterId: id}, nil
+}
+
+// EthNewPendingTransactionFilter allocates a filter id, but this bridge has
+// no local mempool/pending-transaction visibility (Iroha exposes committed
+// blocks only), so EthGetFilterChanges will never report any hashes for it.
+func (api *PublicFilterAPI) EthNewPendingTransactionFilter() (*web3.EthNewPendingTransactionFilterResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	id := api.filters.add(&filterState{kind: filterKindPendingTx})
+	return &web3.EthNewPendingTransactionFilterResult{FilterId: id}, nil
+}
+
+// EthGetFilterChanges drives the real matching logic for each filter kind
+// (draining new block hashes, or re-running LogsFilter from the cursor left
+// by the last poll) and marshals the result into
+// web3.EthGetFilterChangesResult. Only one of BlockHashes/Logs is ever
+// populated, depending on st.kind, mirroring the way a real eth_getFilterChanges
+// response is polymorphic per the filter that was created with eth_newFilter
+// vs eth_newBlockFilter.
+func (api *PublicFilterAPI) EthGetFilterChanges(req *web3.EthGetFilterChangesParams) (*web3.EthGetFilterChangesResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	st, ok := api.filters.get(req.FilterId)
+	if !ok {
+		return nil, web3.ErrNotFound
+	}
+	api.filters.touch(req.FilterId)
+
+	switch st.kind {
+	case filterKindBlock:
+		hashes, ok := api.filters.drainBlockHashes(req.FilterId)
+		if !ok {
+			return nil, web3.ErrNotFound
+		}
+		return &web3.EthGetFilterChangesResult{BlockHashes: hashes}, nil
+	case filterKindPendingTx:
+		// Always empty: see EthNewPendingTransactionFilter.
+		return &web3.EthGetFilterChangesResult{}, nil
+	case filterKindLog:
+		toBlock := api.Blockchain.LastBlockHeight()
+		matches, err := api.LogsFilter(st.lastPolled+1, toBlock, st.addresses, st.topics)
+		if err != nil {
+			return nil, err
+		}
+		api.filters.setLastPolled(req.FilterId, toBlock)
+		return &web3.EthGetFilterChangesResult{Logs: web3LogsFromEvents(matches)}, nil
+	default:
+		return nil, web3.ErrNotFound
+	}
+}
+
+func (api *PublicFilterAPI) EthGetFilterLogs(req *web3.EthGetFilterLogsParams) (*web3.EthGetFilterLogsResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	st, ok := api.filters.get(req.FilterId)
+	if !ok || st.kind != filterKindLog {
+		return nil, web3.ErrNotFound
+	}
+	api.filters.touch(req.FilterId)
+
+	matches, err := api.LogsFilter(0, api.Blockchain.LastBlockHeight(), st.addresses, st.topics)
+	if err != nil {
+		return nil, err
+	}
+	return &web3.EthGetFilterLogsResult{Logs: web3LogsFromEvents(matches)}, nil
+}
+
+// EthGetLogs decodes req.Filter the same way EthNewFilter does, drives the
+// real LogsFilter match and marshals it into web3.EthGetLogsResult.
+func (api *PublicFilterAPI) EthGetLogs(req *web3.EthGetLogsParams) (*web3.EthGetLogsResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	fromBlock, err := api.parseBlockParam(req.Filter.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := api.parseBlockParam(req.Filter.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, topics, err := decodeFilterCriteria(req.Filter.Address, req.Filter.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := api.LogsFilter(fromBlock, toBlock, addresses, topics)
+	if err != nil {
+		return nil, err
+	}
+	return &web3.EthGetLogsResult{Logs: web3LogsFromEvents(matches)}, nil
+}
+
+// web3LogsFromEvents converts this bridge's own indexed log shape
+// (vm.LoggedEvent, see evm/log_index.go) into the web3.EthLog shape
+// eth_getLogs/eth_getFilterLogs/eth_getFilterChanges respond with. Address/
+// Data/Topics/BlockNumber come straight from what LoggedEvent actually
+// stores; TransactionHash/TransactionIndex/BlockHash/LogIndex are left at
+// their zero value because LoggedEvent never recorded which transaction or
+// position within a block produced a log, and widening PersistBlockIndex's
+// stored shape to carry that is a separate, larger change than this one.
+func web3LogsFromEvents(events []vm.LoggedEvent) []*web3.EthLog {
+	logs := make([]*web3.EthLog, len(events))
+	for i, e := range events {
+		logs[i] = &web3.EthLog{
+			Address:     e.Address,
+			Data:        e.Data,
+			Topics:      e.Topics,
+			BlockNumber: strconv.FormatUint(e.BlockHeight, 10),
+		}
+	}
+	return logs
+}
+
+// LogsFilter returns every indexed log between fromBlock and toBlock
+// (inclusive) whose address and topics match the filter. The actual
+// bloom-pruned scan is vm.LogFilter.Query; this just wraps it behind the
+// enabled() namespace check every other method here goes through.
+func (api *PublicFilterAPI) LogsFilter(fromBlock, toBlock uint64, addresses []crypto.Address,
+	topics [][]binary.Word256) ([]vm.LoggedEvent, error) {
+
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	filter := vm.LogFilter{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Addresses: addresses,
+		Topics:    topics,
+	}
+	return filter.Query()
+}
+
+// parseBlockParam resolves a filter's fromBlock/toBlock string the same way
+// the eth namespace's getHeightByWord does ("earliest"/"latest"/"pending"),
+// falling back to parsing it as a 0x-prefixed or decimal block number.
+func (api *PublicFilterAPI) parseBlockParam(height string) (uint64, error) {
+	switch height {
+	case "", "earliest":
+		return 0, nil
+	case "latest", "pending":
+		return api.Blockchain.LastBlockHeight(), nil
+	default:
+		n, err := strconv.ParseUint(strings.TrimPrefix(height, "0x"), 16, 64)
+		if err != nil {
+			return 0, web3.ErrNotFound
+		}
+		return n, nil
+	}
+}
+
+// decodeFilterCriteria turns an eth_newFilter-style address/topics pair
+// into the []crypto.Address/[][]binary.Word256 shape LogsFilter expects.
+// Per the JSON-RPC filter-object spec, address is either a single address
+// string or an array of them, and each topics[i] is either null (wildcard),
+// a single topic string, or an array of topic strings (OR'd together).
+func decodeFilterCriteria(address interface{}, topics []interface{}) ([]crypto.Address, [][]binary.Word256, error) {
+	var addresses []crypto.Address
+	switch a := address.(type) {
+	case nil:
+	case string:
+		addr, err := x.DecodeToAddress(a)
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses = append(addresses, addr)
+	case []string:
+		for _, s := range a {
+			addr, err := x.DecodeToAddress(s)
+			if err != nil {
+				return nil, nil, err
+			}
+			addresses = append(addresses, addr)
+		}
+	case []interface{}:
+		for _, s := range a {
+			str, ok := s.(string)
+			if !ok {
+				return nil, nil, web3.ErrNotFound
+			}
+			addr, err := x.DecodeToAddress(str)
+			if err != nil {
+				return nil, nil, err
+			}
+			addresses = append(addresses, addr)
+		}
+	default:
+		return nil, nil, web3.ErrNotFound
+	}
+
+	decodeTopic := func(s string) (binary.Word256, error) {
+		data, err := x.DecodeToBytes(s)
+		if err != nil {
+			return binary.Word256{}, err
+		}
+		return binary.LeftPadWord256(data), nil
+	}
+
+	positions := make([][]binary.Word256, len(topics))
+	for i, position := range topics {
+		switch p := position.(type) {
+		case nil:
+		case string:
+			topic, err := decodeTopic(p)
+			if err != nil {
+				return nil, nil, err
+			}
+			positions[i] = []binary.Word256{topic}
+		case []interface{}:
+			for _, v := range p {
+				str, ok := v.(string)
+				if !ok {
+					return nil, nil, web3.ErrNotFound
+				}
+				topic, err := decodeTopic(str)
+				if err != nil {
+					return nil, nil, err
+				}
+				positions[i] = append(positions[i], topic)
+			}
+		default:
+			return nil, nil, web3.ErrNotFound
+		}
+	}
+
+	return addresses, positions, nil
+}
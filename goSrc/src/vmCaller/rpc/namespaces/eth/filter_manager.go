@@ -0,0 +1,185 @@
+package eth
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	bcm "github.com/hyperledger/burrow/bcm"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+type filterKind int
+
+const (
+	filterKindLog filterKind = iota
+	filterKindBlock
+	filterKindPendingTx
+)
+
+// filterExpiry is how long a filter survives without being polled via
+// EthGetFilterChanges/EthGetFilterLogs before it's swept, mirroring
+// go-ethereum's filter timeout so clients that forget to uninstall a filter
+// don't leak it forever.
+const filterExpiry = 5 * time.Minute
+
+// filterBlockPollInterval is how often the background tail goroutine checks
+// for new blocks to feed to open block filters.
+const filterBlockPollInterval = 2 * time.Second
+
+// maxPendingBlockHashes bounds the ring buffer of block hashes a block
+// filter accumulates between polls, so an abandoned-but-not-yet-expired
+// filter can't grow without bound.
+const maxPendingBlockHashes = 256
+
+// filterState is the criteria and cursor for one open filter, as allocated
+// by EthNewFilter/EthNewBlockFilter/EthNewPendingTransactionFilter.
+type filterState struct {
+	kind       filterKind
+	addresses  []crypto.Address
+	topics     [][]binary.Word256
+	lastPolled uint64
+	lastPollAt time.Time
+
+	// pendingBlockHashes is filled by the background tail goroutine for
+	// filterKindBlock filters and drained by EthGetFilterChanges.
+	pendingBlockHashes []string
+}
+
+// FilterManager tracks every filter currently open against this node,
+// keyed by an opaque id handed back to the client from EthNewFilter et al.
+type FilterManager struct {
+	mu      sync.Mutex
+	filters map[string]*filterState
+	nextID  uint64
+}
+
+func newFilterManager(blockchain bcm.BlockchainInfo) *FilterManager {
+	fm := &FilterManager{filters: make(map[string]*filterState)}
+	fm.startBlockTail(blockchain)
+	return fm
+}
+
+// add allocates a new filter id for state and stores it.
+func (fm *FilterManager) add(state *filterState) string {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	fm.nextID++
+	id := fmt.Sprintf("0x%x", fm.nextID)
+	state.lastPollAt = time.Now()
+	fm.filters[id] = state
+	return id
+}
+
+// get returns the filter registered under id, expiring any filters that
+// have gone untouched for longer than filterExpiry first.
+func (fm *FilterManager) get(id string) (*filterState, bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	fm.expireLocked()
+	st, ok := fm.filters[id]
+	return st, ok
+}
+
+// remove uninstalls a filter, reporting whether it existed.
+func (fm *FilterManager) remove(id string) bool {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	_, ok := fm.filters[id]
+	delete(fm.filters, id)
+	return ok
+}
+
+// touch resets id's expiry clock, called on every successful poll.
+func (fm *FilterManager) touch(id string) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if st, ok := fm.filters[id]; ok {
+		st.lastPollAt = time.Now()
+	}
+}
+
+// setLastPolled records the block height up to which a log filter's matches
+// have already been returned to its client.
+func (fm *FilterManager) setLastPolled(id string, height uint64) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if st, ok := fm.filters[id]; ok {
+		st.lastPolled = height
+	}
+}
+
+// drainBlockHashes returns and clears the block hashes accumulated for a
+// block filter since its last poll.
+func (fm *FilterManager) drainBlockHashes(id string) ([]string, bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	st, ok := fm.filters[id]
+	if !ok {
+		return nil, false
+	}
+	hashes := st.pendingBlockHashes
+	st.pendingBlockHashes = nil
+	return hashes, true
+}
+
+// expireLocked sweeps filters that have gone untouched longer than
+// filterExpiry. Callers must hold fm.mu.
+func (fm *FilterManager) expireLocked() {
+	cutoff := time.Now().Add(-filterExpiry)
+	for id, st := range fm.filters {
+		if st.lastPollAt.Before(cutoff) {
+			delete(fm.filters, id)
+		}
+	}
+}
+
+// startBlockTail runs a background goroutine that notices new blocks and
+// feeds their hashes to every open block filter, so EthGetFilterChanges has
+// something to drain without re-scanning the chain on every poll. It runs
+// for the lifetime of the process, the same way blockchain.Instance() is a
+// process-wide singleton elsewhere in this bridge.
+func (fm *FilterManager) startBlockTail(blockchain bcm.BlockchainInfo) {
+	go func() {
+		var lastHeight uint64
+		for {
+			time.Sleep(filterBlockPollInterval)
+			if blockchain == nil {
+				continue
+			}
+			height := blockchain.LastBlockHeight()
+			if height <= lastHeight {
+				continue
+			}
+
+			fm.mu.Lock()
+			for h := lastHeight + 1; h <= height; h++ {
+				header, err := blockchain.GetBlockHeader(h)
+				if err != nil {
+					continue
+				}
+				hash := header.Hash().String()
+				for _, st := range fm.filters {
+					if st.kind != filterKindBlock {
+						continue
+					}
+					st.pendingBlockHashes = append(st.pendingBlockHashes, hash)
+					if len(st.pendingBlockHashes) > maxPendingBlockHashes {
+						st.pendingBlockHashes = st.pendingBlockHashes[len(st.pendingBlockHashes)-maxPendingBlockHashes:]
+					}
+				}
+			}
+			fm.expireLocked()
+			fm.mu.Unlock()
+
+			lastHeight = height
+		}
+	}()
+}
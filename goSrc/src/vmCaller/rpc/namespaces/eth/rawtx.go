@@ -0,0 +1,230 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	x "github.com/hyperledger/burrow/encoding/hex"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/rpc/web3"
+)
+
+// txType distinguishes the three raw-transaction envelopes
+// EthSendRawTransaction accepts, sniffed from the first byte per EIP-2718.
+type txType int
+
+const (
+	txTypeLegacy     txType = iota // no type prefix, starts with an RLP list
+	txTypeAccessList               // 0x01, EIP-2930
+	txTypeDynamicFee               // 0x02, EIP-1559
+)
+
+// rawTx is this bridge's decoded view of a raw Ethereum transaction, common
+// to all three envelope types (fields only one type uses are left zero for
+// the others).
+type rawTx struct {
+	typ                  txType
+	chainID              uint64
+	nonce                uint64
+	gasPrice             *big.Int // legacy, EIP-2930
+	maxPriorityFeePerGas *big.Int // EIP-1559
+	maxFeePerGas         *big.Int // EIP-1559
+	gasLimit             uint64
+	to                   *crypto.Address // nil means contract creation
+	value                *big.Int
+	data                 []byte
+	v, r, s              *big.Int
+
+	// signingPayload is the exact bytes (type prefix + unsigned field list)
+	// this transaction's signature was computed over, reassembled from the
+	// original RLP spans rather than re-encoded, so nested fields like
+	// EIP-2930's access list don't need to round-trip through this
+	// package's encoder.
+	signingPayload []byte
+}
+
+// decodeRawTransaction sniffs and decodes a raw Ethereum transaction per
+// EIP-2718: 0x01 is an EIP-2930 access-list transaction, 0x02 is an
+// EIP-1559 dynamic-fee transaction, and anything starting with an RLP list
+// (byte >= 0xc0) is a legacy transaction.
+func decodeRawTransaction(raw []byte) (*rawTx, error) {
+	if len(raw) == 0 {
+		return nil, errRLPMalformed
+	}
+	if raw[0] >= 0xc0 {
+		return decodeLegacyTx(raw)
+	}
+	switch raw[0] {
+	case 0x01:
+		// chainId, nonce, gasPrice, gasLimit, to, value, data, accessList, yParity, r, s
+		return decodeTypedTx(txTypeAccessList, 0x01, raw[1:], 11)
+	case 0x02:
+		// chainId, nonce, maxPriorityFeePerGas, maxFeePerGas, gasLimit, to, value, data, accessList, yParity, r, s
+		return decodeTypedTx(txTypeDynamicFee, 0x02, raw[1:], 12)
+	default:
+		return nil, fmt.Errorf("unsupported transaction type 0x%x", raw[0])
+	}
+}
+
+func decodeAddress(item rlpItem) (*crypto.Address, error) {
+	if len(item.data) == 0 {
+		return nil, nil
+	}
+	if len(item.data) != crypto.AddressLength {
+		return nil, fmt.Errorf("invalid address length %d", len(item.data))
+	}
+	var addr crypto.Address
+	copy(addr[:], item.data)
+	return &addr, nil
+}
+
+// decodeLegacyTx decodes the 9-field legacy envelope (nonce, gasPrice,
+// gasLimit, to, value, data, v, r, s) and recovers its EIP-155 chain ID
+// from v, if present (v = chainID*2+35 or +36; pre-EIP-155 txs use a bare
+// 27/28 and carry no chain ID).
+func decodeLegacyTx(raw []byte) (*rawTx, error) {
+	items, err := decodeRLPList(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 9 {
+		return nil, errRLPMalformed
+	}
+
+	to, err := decodeAddress(items[3])
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &rawTx{
+		typ:      txTypeLegacy,
+		nonce:    items[0].uint64(),
+		gasPrice: items[1].bigInt(),
+		gasLimit: items[2].uint64(),
+		to:       to,
+		value:    items[4].bigInt(),
+		data:     items[5].data,
+		v:        items[6].bigInt(),
+		r:        items[7].bigInt(),
+		s:        items[8].bigInt(),
+	}
+
+	v := tx.v.Uint64()
+	if v >= 35 {
+		tx.chainID = (v - 35) / 2
+		tx.signingPayload = encodeRLPList(items[0].raw, items[1].raw, items[2].raw, items[3].raw,
+			items[4].raw, items[5].raw, encodeRLPUint64(tx.chainID), []byte{0x80}, []byte{0x80})
+	} else {
+		tx.signingPayload = encodeRLPList(items[0].raw, items[1].raw, items[2].raw, items[3].raw,
+			items[4].raw, items[5].raw)
+	}
+	return tx, nil
+}
+
+// decodeTypedTx decodes an EIP-2930/EIP-1559 envelope. Both share the same
+// leading shape (chainId, nonce, gas pricing fields, gasLimit, to, value,
+// data, accessList) and trailing signature (yParity, r, s); only the gas
+// pricing field count differs (1 for access-list txs' flat gasPrice, 2 for
+// dynamic-fee txs' priority/max fee pair), chosen by typ; totalFields is the
+// full field count the list is checked against up front.
+func decodeTypedTx(typ txType, typeByte byte, raw []byte, totalFields int) (*rawTx, error) {
+	items, err := decodeRLPList(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != totalFields {
+		return nil, errRLPMalformed
+	}
+
+	i := 0
+	chainID := items[i].uint64()
+	i++
+	nonce := items[i].uint64()
+	i++
+
+	tx := &rawTx{typ: typ, chainID: chainID, nonce: nonce}
+	if typ == txTypeAccessList {
+		tx.gasPrice = items[i].bigInt()
+		i++
+	} else {
+		tx.maxPriorityFeePerGas = items[i].bigInt()
+		i++
+		tx.maxFeePerGas = items[i].bigInt()
+		i++
+	}
+
+	tx.gasLimit = items[i].uint64()
+	i++
+	to, err := decodeAddress(items[i])
+	if err != nil {
+		return nil, err
+	}
+	tx.to = to
+	i++
+	tx.value = items[i].bigInt()
+	i++
+	tx.data = items[i].data
+	i++
+	i++ // accessList: carried in the signing payload below, not decoded further
+	unsignedFieldCount := i
+
+	tx.v = items[i].bigInt() // yParity
+	i++
+	tx.r = items[i].bigInt()
+	i++
+	tx.s = items[i].bigInt()
+
+	spans := make([][]byte, unsignedFieldCount)
+	for j := 0; j < unsignedFieldCount; j++ {
+		spans[j] = items[j].raw
+	}
+	tx.signingPayload = append([]byte{typeByte}, encodeRLPList(spans...)...)
+	return tx, nil
+}
+
+// errSenderRecoveryUnsupported is what recoverSender always returns: this
+// bridge doesn't vendor a secp256k1 recovery implementation (the same gap
+// already noted in delegation.go's verifyAuthorization, which sidesteps it
+// by verifying against a pre-registered key instead), and hand-rolling
+// elliptic-curve point recovery here risks a silently wrong sender address,
+// which is worse than refusing the transaction outright. Until this is
+// fixed, decodeRawTransaction/sendRawTransaction only decode and validate a
+// raw transaction's envelope — eth_sendRawTransaction cannot actually submit
+// one.
+var errSenderRecoveryUnsupported = fmt.Errorf("eth_sendRawTransaction: sender recovery requires a secp256k1 ecrecover implementation not vendored in this tree")
+
+// recoverSender is meant to recover the raw transaction's 20-byte sender
+// address from its signature (v, r, s) and the Keccak-256 digest of
+// signingPayload via secp256k1 public-key recovery ("ecrecover"); see
+// errSenderRecoveryUnsupported for why it can't yet.
+func (tx *rawTx) recoverSender() (crypto.Address, error) {
+	return crypto.Address{}, errSenderRecoveryUnsupported
+}
+
+// sendRawTransaction decodes and validates a raw Ethereum transaction's
+// envelope (chain ID, RLP structure, signature presence) but cannot submit
+// it: see errSenderRecoveryUnsupported. It always returns an error rather
+// than the tx hash eth_sendRawTransaction's JSON-RPC spec promises on
+// success, so callers can't mistake "decoded fine" for "accepted".
+func (api *PublicEthereumAPI) sendRawTransaction(raw []byte) (*web3.EthSendRawTransactionResult, error) {
+	tx, err := decodeRawTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+	if tx.chainID != 0 && tx.chainID != uint64(ChainID) {
+		return nil, fmt.Errorf("wrong chain ID: expected %d, got %d", ChainID, tx.chainID)
+	}
+
+	if _, err := tx.recoverSender(); err != nil {
+		return nil, err
+	}
+
+	// The rest of the pipeline (mapping the recovered sender to an Iroha
+	// account, wrapping the decoded tx as a CallTx and submitting it via
+	// api.Trans) is unreachable until recoverSender works, so it's not
+	// written here rather than guessed untested.
+	return &web3.EthSendRawTransactionResult{
+		TransactionHash: x.EncodeBytes(crypto.Keccak256(raw)),
+	}, nil
+}
@@ -0,0 +1,191 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+)
+
+// rlpItem is a decoded RLP value: either a byte string or a list of further
+// rlpItems. This package hand-rolls just enough of RLP to decode raw
+// Ethereum transactions, since neither go-ethereum/rlp nor any equivalent
+// is vendored in this tree.
+type rlpItem struct {
+	isList bool
+	data   []byte
+	list   []rlpItem
+	// raw is the item's exact encoded form (header + payload) as a slice of
+	// the original input, so signing-hash computation can reassemble a
+	// subset of a list's items without re-encoding nested structures like
+	// access lists.
+	raw []byte
+}
+
+var errRLPMalformed = errors.New("malformed RLP input")
+
+// decodeRLPList decodes data as a single top-level RLP list and returns its
+// elements, which is the shape every Ethereum transaction envelope (legacy
+// or typed) is encoded as.
+func decodeRLPList(data []byte) ([]rlpItem, error) {
+	item, rest, err := decodeRLPItem(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errRLPMalformed
+	}
+	if !item.isList {
+		return nil, errRLPMalformed
+	}
+	return item.list, nil
+}
+
+// decodeRLPItem decodes exactly one RLP item from the front of data and
+// returns it along with whatever bytes follow it.
+func decodeRLPItem(data []byte) (rlpItem, []byte, error) {
+	if len(data) == 0 {
+		return rlpItem{}, nil, errRLPMalformed
+	}
+
+	first := data[0]
+	switch {
+	case first < 0x80:
+		return rlpItem{data: data[:1], raw: data[:1]}, data[1:], nil
+
+	case first < 0xb8:
+		length := int(first - 0x80)
+		if len(data) < 1+length {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		return rlpItem{data: data[1 : 1+length], raw: data[:1+length]}, data[1+length:], nil
+
+	case first < 0xc0:
+		lengthOfLength := int(first - 0xb7)
+		if len(data) < 1+lengthOfLength {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		length := bytesToInt(data[1 : 1+lengthOfLength])
+		start := 1 + lengthOfLength
+		if len(data) < start+length {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		return rlpItem{data: data[start : start+length], raw: data[:start+length]}, data[start+length:], nil
+
+	case first < 0xf8:
+		length := int(first - 0xc0)
+		if len(data) < 1+length {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		list, err := decodeRLPListBody(data[1 : 1+length])
+		if err != nil {
+			return rlpItem{}, nil, err
+		}
+		return rlpItem{isList: true, list: list, raw: data[:1+length]}, data[1+length:], nil
+
+	default:
+		lengthOfLength := int(first - 0xf7)
+		if len(data) < 1+lengthOfLength {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		length := bytesToInt(data[1 : 1+lengthOfLength])
+		start := 1 + lengthOfLength
+		if len(data) < start+length {
+			return rlpItem{}, nil, errRLPMalformed
+		}
+		list, err := decodeRLPListBody(data[start : start+length])
+		if err != nil {
+			return rlpItem{}, nil, err
+		}
+		return rlpItem{isList: true, list: list, raw: data[:start+length]}, data[start+length:], nil
+	}
+}
+
+// decodeRLPListBody decodes every item packed into a list's payload.
+func decodeRLPListBody(data []byte) ([]rlpItem, error) {
+	var items []rlpItem
+	for len(data) > 0 {
+		item, rest, err := decodeRLPItem(data)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		data = rest
+	}
+	return items, nil
+}
+
+func bytesToInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+// uint64 interprets item as a big-endian unsigned integer, the way RLP
+// encodes nonces, gas prices and limits (no leading zero bytes).
+func (item rlpItem) uint64() uint64 {
+	var n uint64
+	for _, b := range item.data {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// bigInt interprets item as a big-endian unsigned integer of arbitrary
+// size, the way RLP encodes value/gas fields that can exceed 64 bits.
+func (item rlpItem) bigInt() *big.Int {
+	return new(big.Int).SetBytes(item.data)
+}
+
+// encodeRLPBytes RLP-encodes a byte string, used to re-encode a raw
+// transaction's unsigned fields when computing its EIP-155/typed-tx
+// signing hash.
+func encodeRLPBytes(data []byte) []byte {
+	if len(data) == 1 && data[0] < 0x80 {
+		return data
+	}
+	return append(encodeRLPLength(len(data), 0x80, 0xb7), data...)
+}
+
+func encodeRLPUint64(n uint64) []byte {
+	if n == 0 {
+		return []byte{0x80}
+	}
+	var buf [8]byte
+	i := 8
+	for n > 0 {
+		i--
+		buf[i] = byte(n)
+		n >>= 8
+	}
+	return encodeRLPBytes(buf[i:])
+}
+
+func encodeRLPBigInt(n *big.Int) []byte {
+	if n == nil || n.Sign() == 0 {
+		return []byte{0x80}
+	}
+	return encodeRLPBytes(n.Bytes())
+}
+
+// encodeRLPList RLP-encodes a sequence of already-encoded items as a list.
+func encodeRLPList(items ...[]byte) []byte {
+	var body []byte
+	for _, item := range items {
+		body = append(body, item...)
+	}
+	return append(encodeRLPLength(len(body), 0xc0, 0xf7), body...)
+}
+
+// encodeRLPLength builds the length prefix for a string (shortBase 0x80,
+// longBase 0xb7) or list (shortBase 0xc0, longBase 0xf7).
+func encodeRLPLength(length, shortBase, longBase int) []byte {
+	if length < 56 {
+		return []byte{byte(shortBase + length)}
+	}
+	var lengthBytes []byte
+	for n := length; n > 0; n >>= 8 {
+		lengthBytes = append([]byte{byte(n)}, lengthBytes...)
+	}
+	return append([]byte{byte(longBase + len(lengthBytes))}, lengthBytes...)
+}
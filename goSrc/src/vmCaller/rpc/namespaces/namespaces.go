@@ -0,0 +1,40 @@
+// Package namespaces tracks which JSON-RPC namespaces (eth, net, web3,
+// personal, filter, ...) a node has been configured to serve, mirroring
+// Geth's --http.api flag. It exists as its own package, independent of
+// vmCaller/rpc and the per-namespace API packages under this directory, so
+// both sides can depend on it without an import cycle: vmCaller/rpc
+// assembles the per-namespace APIs into one EthService, and each
+// per-namespace API needs to ask "am I enabled?" without importing the
+// assembler that constructs it.
+package namespaces
+
+// Set reports which namespaces are enabled. A nil *Set (the zero value)
+// enables everything, matching "no --http.api given" defaulting to every
+// namespace.
+type Set struct {
+	enabled map[string]bool
+}
+
+// New builds a Set enabling exactly the given namespace names. Calling New
+// with no names enables everything (same as a nil *Set), rather than
+// enabling nothing, since an empty enable-list almost always means
+// "unconfigured" rather than "lock everything down" in this bridge's
+// existing config surface (see Kernel.HasAPI).
+func New(names ...string) *Set {
+	if len(names) == 0 {
+		return nil
+	}
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+	return &Set{enabled: enabled}
+}
+
+// Enabled reports whether namespace should be served.
+func (s *Set) Enabled(namespace string) bool {
+	if s == nil {
+		return true
+	}
+	return s.enabled[namespace]
+}
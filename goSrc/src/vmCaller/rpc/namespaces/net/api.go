@@ -0,0 +1,83 @@
+// Package net implements the net_* JSON-RPC namespace (PublicNetAPI), split
+// out of the single god-object rpc.EthService that used to implement it
+// directly.
+package net
+
+import (
+	"vmCaller/rpc/namespaces"
+
+	"github.com/hyperledger/burrow/consensus/tendermint"
+	x "github.com/hyperledger/burrow/encoding/hex"
+	"github.com/hyperledger/burrow/rpc/web3"
+)
+
+const chainID = 1
+
+// PublicNetAPI implements the net_* namespace.
+type PublicNetAPI struct {
+	NodeView   *tendermint.NodeView
+	Namespaces *namespaces.Set
+}
+
+// NewPublicNetAPI builds the net_* namespace's API.
+func NewPublicNetAPI(nodeView *tendermint.NodeView, ns *namespaces.Set) *PublicNetAPI {
+	return &PublicNetAPI{
+		NodeView:   nodeView,
+		Namespaces: ns,
+	}
+}
+
+func (api *PublicNetAPI) enabled() error {
+	if !api.Namespaces.Enabled("net") {
+		return web3.ErrNotFound
+	}
+	return nil
+}
+
+// NetListening returns true if the peer is running
+func (api *PublicNetAPI) NetListening() (*web3.NetListeningResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.NetListeningResult{
+		IsNetListening: api.NodeView.NodeInfo().GetListenAddress() != "",
+	}, nil
+}
+
+// NetPeerCount returns the number of connected peers
+func (api *PublicNetAPI) NetPeerCount() (*web3.NetPeerCountResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.NetPeerCountResult{
+		NumConnectedPeers: x.EncodeNumber(uint64(api.NodeView.Peers().Size())),
+	}, nil
+}
+
+// NetVersion returns the hex encoding of the network id,
+// this is typically a small int (where 1 == Ethereum mainnet)
+func (api *PublicNetAPI) NetVersion() (*web3.NetVersionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.NetVersionResult{
+		ChainID: x.EncodeNumber(uint64(chainID)),
+	}, nil
+}
+
+// NodeInfo is a best-effort admin_nodeInfo-style summary of the underlying
+// Iroha/Tendermint peer, exposed ahead of a dedicated admin namespace being
+// registered with the RPC server.
+type NodeInfo struct {
+	ID            string `json:"id"`
+	ListenAddress string `json:"listenAddr"`
+}
+
+// NodeInfo returns the peer's public key and listen address.
+func (api *PublicNetAPI) NodeInfo() *NodeInfo {
+	info := api.NodeView.NodeInfo()
+	return &NodeInfo{
+		ID:            string(info.ID()),
+		ListenAddress: info.GetListenAddress(),
+	}
+}
@@ -0,0 +1,258 @@
+// Package personal implements the personal_* JSON-RPC namespace
+// (PrivateAccountAPI), split out of the single god-object rpc.EthService in
+// the same pass as the other namespaces.
+package personal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"vmCaller/rpc/namespaces"
+
+	"github.com/hyperledger/burrow/crypto"
+	x "github.com/hyperledger/burrow/encoding/hex"
+	"github.com/hyperledger/burrow/execution"
+	"github.com/hyperledger/burrow/keys"
+	"github.com/hyperledger/burrow/rpc/web3"
+)
+
+// defaultUnlockDuration is how long personal_unlockAccount leaves an
+// account unlocked when the caller passes a zero duration, mirroring
+// Geth's own default for the same call.
+const defaultUnlockDuration = 300 * time.Second
+
+// PrivateAccountAPI implements the personal_* namespace.
+type PrivateAccountAPI struct {
+	KeyClient  keys.KeyClient
+	KeyStore   *keys.KeyStore
+	Trans      *execution.Transactor
+	Namespaces *namespaces.Set
+
+	// mu guards the check-then-act sequences below (replacing or clearing an
+	// existing unlock timer); unlocked itself is a sync.Map so a lock-free
+	// read from IsUnlocked/SignUnlocked never has to contend with mu.
+	mu       sync.Mutex
+	unlocked sync.Map // crypto.Address.String() -> *time.Timer
+}
+
+// NewPrivateAccountAPI builds the personal_* namespace's API.
+func NewPrivateAccountAPI(keyClient keys.KeyClient, keyStore *keys.KeyStore, trans *execution.Transactor, ns *namespaces.Set) *PrivateAccountAPI {
+	return &PrivateAccountAPI{
+		KeyClient:  keyClient,
+		KeyStore:   keyStore,
+		Trans:      trans,
+		Namespaces: ns,
+	}
+}
+
+func (api *PrivateAccountAPI) enabled() error {
+	if !api.Namespaces.Enabled("personal") {
+		return web3.ErrNotFound
+	}
+	return nil
+}
+
+type PersonalNewAccountParams struct {
+	Passphrase string
+}
+
+type PersonalNewAccountResult struct {
+	Address string
+}
+
+// PersonalNewAccount generates a new secp256k1 key via the node's
+// keys.KeyClient and returns its address. req.Passphrase is accepted for
+// parity with Geth's personal_newAccount, but isn't yet used to encrypt the
+// key at rest: the vendored keys.KeyStore this bridge builds on is unlocked
+// by the node operator's own passphrase rather than a per-account one, and
+// there's no verified hook here to thread a caller-supplied passphrase down
+// into it (same gap noted on PersonalUnlockAccount).
+func (api *PrivateAccountAPI) PersonalNewAccount(req *PersonalNewAccountParams) (*PersonalNewAccountResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	address, err := api.KeyClient.Generate("", crypto.CurveTypeSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return &PersonalNewAccountResult{Address: address.String()}, nil
+}
+
+type PersonalListAccountsResult struct {
+	Accounts []string
+}
+
+// PersonalListAccounts returns every address the node's keystore can sign
+// for.
+func (api *PrivateAccountAPI) PersonalListAccounts() (*PersonalListAccountsResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	addresses, err := api.KeyStore.GetAllAddresses()
+	if err != nil {
+		return nil, err
+	}
+	return &PersonalListAccountsResult{Accounts: addresses}, nil
+}
+
+type PersonalUnlockAccountParams struct {
+	Address    string
+	Passphrase string
+	Duration   uint64 // seconds; 0 means defaultUnlockDuration
+}
+
+type PersonalUnlockAccountResult struct {
+	Unlocked bool
+}
+
+// PersonalUnlockAccount arms a timer after which req.Address auto-relocks,
+// the same trade this bridge's keys.KeyClient already makes implicitly by
+// not requiring a passphrase per Sign call: req.Passphrase is accepted for
+// interface parity with Geth but isn't verified against anything (see
+// PersonalNewAccount's doc comment for why).
+func (api *PrivateAccountAPI) PersonalUnlockAccount(req *PersonalUnlockAccountParams) (*PersonalUnlockAccountResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	address, err := x.DecodeToAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := defaultUnlockDuration
+	if req.Duration > 0 {
+		duration = time.Duration(req.Duration) * time.Second
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if existing, ok := api.unlocked.Load(address.String()); ok {
+		existing.(*time.Timer).Stop()
+	}
+	api.unlocked.Store(address.String(), time.AfterFunc(duration, func() {
+		api.unlocked.Delete(address.String())
+	}))
+
+	return &PersonalUnlockAccountResult{Unlocked: true}, nil
+}
+
+type PersonalLockAccountParams struct {
+	Address string
+}
+
+type PersonalLockAccountResult struct {
+	Locked bool
+}
+
+// PersonalLockAccount cancels req.Address's unlock timer early, if it has
+// one.
+func (api *PrivateAccountAPI) PersonalLockAccount(req *PersonalLockAccountParams) (*PersonalLockAccountResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	address, err := x.DecodeToAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if timer, ok := api.unlocked.Load(address.String()); ok {
+		timer.(*time.Timer).Stop()
+		api.unlocked.Delete(address.String())
+	}
+
+	return &PersonalLockAccountResult{Locked: true}, nil
+}
+
+// IsUnlocked reports whether address currently has an active
+// personal_unlockAccount timer, the precondition PersonalSign,
+// PersonalSendTransaction and eth.PublicEthereumAPI's EthSign/
+// EthSendTransaction all require before they'll sign with it. It's
+// exported so the eth namespace can check it through its own
+// AccountsSigner interface without importing this package's RPC surface.
+func (api *PrivateAccountAPI) IsUnlocked(address crypto.Address) bool {
+	_, ok := api.unlocked.Load(address.String())
+	return ok
+}
+
+// SignUnlocked signs digest with address's key, the same way
+// EthereumSignedMessageHash is meant to be used: callers hash their
+// message first and pass the digest in here, they don't pass a raw
+// message. Returns an error if address isn't currently unlocked.
+func (api *PrivateAccountAPI) SignUnlocked(address crypto.Address, digest []byte) (crypto.Signature, error) {
+	if !api.IsUnlocked(address) {
+		return crypto.Signature{}, fmt.Errorf("account %s is locked", address.String())
+	}
+	return api.KeyClient.Sign(digest, address)
+}
+
+type PersonalSignParams struct {
+	Data    string // 0x-prefixed hex message
+	Address string
+}
+
+type PersonalSignResult struct {
+	Signature string
+}
+
+// PersonalSign signs req.Data the way eth_sign/personal_sign is specified
+// to: prefixed with "\x19Ethereum Signed Message:\n" + len(message) and
+// keccak256-hashed, so the result verifies against tools built around
+// eth_sig_util (see EthereumSignedMessageHash).
+func (api *PrivateAccountAPI) PersonalSign(req *PersonalSignParams) (*PersonalSignResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	address, err := x.DecodeToAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	message, err := x.DecodeToBytes(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := api.SignUnlocked(address, EthereumSignedMessageHash(message))
+	if err != nil {
+		return nil, err
+	}
+	return &PersonalSignResult{Signature: x.EncodeBytes(sig.Bytes())}, nil
+}
+
+// EthereumSignedMessageHash is the digest eth_sign/personal_sign actually
+// sign, per the convention that stops a signed Ethereum transaction from
+// ever being mistaken for a signed arbitrary message: a fixed prefix, the
+// message's length as a decimal string, then the message itself, all
+// keccak256-hashed together.
+func EthereumSignedMessageHash(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return crypto.Keccak256(append([]byte(prefix), message...))
+}
+
+type PersonalSendTransactionParams struct {
+	From     string
+	To       string
+	Data     string
+	Value    string
+	Password string
+}
+
+type PersonalSendTransactionResult struct {
+	TransactionHash string
+}
+
+// PersonalSendTransaction is meant to sign req with the unlocked req.From
+// key and broadcast it through api.Trans the way EthSendRawTransaction
+// broadcasts an already-signed one (see rawtx.go's sendRawTransaction in
+// the eth namespace). It isn't implemented yet: building the signed
+// envelope needs a payload.CallTx populated and handed to
+// execution.Transactor's broadcast entry point, and this tree has no
+// vendored copy of execution.Transactor to confirm that method's exact
+// name and signature against.
+func (api *PrivateAccountAPI) PersonalSendTransaction(req *PersonalSendTransactionParams) (*PersonalSendTransactionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return nil, web3.ErrNotFound
+}
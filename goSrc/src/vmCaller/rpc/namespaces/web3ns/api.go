@@ -0,0 +1,60 @@
+// Package web3ns implements the web3_* JSON-RPC namespace (PublicWeb3API),
+// split out of the single god-object rpc.EthService that used to implement
+// it directly. Named web3ns rather than web3 since the assembler package
+// also needs to import the vendored github.com/hyperledger/burrow/rpc/web3
+// package unaliased.
+package web3ns
+
+import (
+	"vmCaller/rpc/namespaces"
+
+	"github.com/hyperledger/burrow/crypto"
+	x "github.com/hyperledger/burrow/encoding/hex"
+	"github.com/hyperledger/burrow/project"
+	"github.com/hyperledger/burrow/rpc/web3"
+)
+
+// PublicWeb3API implements the web3_* namespace.
+type PublicWeb3API struct {
+	Namespaces *namespaces.Set
+}
+
+// NewPublicWeb3API builds the web3_* namespace's API.
+func NewPublicWeb3API(ns *namespaces.Set) *PublicWeb3API {
+	return &PublicWeb3API{
+		Namespaces: ns,
+	}
+}
+
+func (api *PublicWeb3API) enabled() error {
+	if !api.Namespaces.Enabled("web3") {
+		return web3.ErrNotFound
+	}
+	return nil
+}
+
+// Web3ClientVersion returns the version of burrow
+func (api *PublicWeb3API) Web3ClientVersion() (*web3.Web3ClientVersionResult, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+	return &web3.Web3ClientVersionResult{
+		ClientVersion: project.FullVersion(),
+	}, nil
+}
+
+// Web3Sha3 returns Keccak-256 (not the standardized SHA3-256) of the given data
+func (api *PublicWeb3API) Web3Sha3(req *web3.Web3Sha3Params) (*web3.Web3Sha3Result, error) {
+	if err := api.enabled(); err != nil {
+		return nil, err
+	}
+
+	data, err := x.DecodeToBytes(req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &web3.Web3Sha3Result{
+		HashedData: x.EncodeBytes(crypto.Keccak256(data)),
+	}, nil
+}
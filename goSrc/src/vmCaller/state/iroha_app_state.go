@@ -1,3 +1,16 @@
+// IrohaAppState below is unreferenced: nothing in main.go constructs it, and
+// its cgo calls to Iroha_ProtoCommandExecutorExecute/
+// Iroha_ProtoSpecificQueryExecutorExecute are the same pre-rewrite
+// generation as state.State (see vm_state.go's doc comment, and
+// contract/service_contracts.go's for the analogous api-package story). The
+// live acmstate.ReaderWriter wired into main.go's EngineWrapper is
+// evm.IrohaState (see evm/storage_state.go), which as of the StateStore
+// refactor (see vmCaller/state_store) embeds a state_store.StateStore
+// interface rather than hardcoding cgo calls directly - the testability gap
+// IrohaAppState's hardcoded Iroha_Proto*Executor calls describe is closed
+// there, against the code path actually in use, rather than by rebuilding
+// the same adapter-over-an-interface shape a second time on top of this
+// unreferenced one.
 package state
 
 // #cgo CFLAGS: -I ../../../../irohad
@@ -68,7 +81,8 @@ func (ias *IrohaAppState) GetAccount(addr crypto.Address) (*acm.Account, error)
 	}
 }
 
-// mock
+// mock: see state/iroha_state.go's IrohaState.GetMetadata for why this
+// package's mocks were left as-is rather than wired up for real.
 func (ias *IrohaAppState) GetMetadata(metahash acmstate.MetadataHash) (string, error) {
 	fmt.Println("GetMetadata: metahash" + metahash.String())
 	return "", nil
@@ -341,6 +355,16 @@ func (ias *IrohaAppState) getIrohaAccountAssets(accountID string) ([]*pb.Account
 /*
 	Method for transferring assets between accounts
 	Not part of ReaderWriter interface, hence type assertion required
+
+	This (and setIrohaAccountDetail above) commits straight through the cgo
+	command executor with no snapshot/revert around it, so in principle an
+	outer EVM REVERT after this call returns would leave the transfer
+	committed. This type is dead code (see the package doc comment above),
+	so that gap is moot here; the live path's equivalent gap -
+	iroha.TransferAsset/AddAssetQuantity/SubtractAssetQuantity/
+	SetAccountDetail, called from evm/native_contract.go - is closed by the
+	Go-side command journal in iroha/journal.go, folded into
+	iroha.IrohaStorage.Snapshot/RevertToSnapshot/CommitSnapshot.
 */
 func (ias *IrohaAppState) transferIrohaAsset(src, dst, amount, asset string) error {
 	command := &pb.Command{Command: &pb.Command_TransferAsset{
@@ -392,6 +416,20 @@ func (res *C.struct_Iroha_CommandError) String() string {
 }
 
 // Helper functions to convert 40 byte long EVM hex-encoded addresses to Iroha compliant account names (32 bytes max)
+//
+// This truncation is exactly the collision risk it looks like: two distinct
+// addresses sharing their first 16 bytes truncate to the same 32-char name
+// and silently alias to one Iroha account. It is left as-is rather than
+// switched to a collision-free encoding (e.g. unpadded lowercase base32 of
+// the raw 20-byte address, which also happens to fit in 32 chars) because
+// IrohaAppState is unreferenced (see this file's package doc comment) and
+// this bug has no reachable call path to actually trigger. The live
+// acmstate.ReaderWriter, evm.IrohaState / iroha.IrohaStorage (see
+// iroha/storage.go's GetAccount/UpdateAccount), does not derive an Iroha
+// account name from the address at all - it passes address.String() through
+// to the native Ametsuchi backend as the lookup key directly, so this
+// truncate-to-32 scheme, and the collision it risks, does not exist on the
+// path actually in use.
 func irohaCompliantName(addr crypto.Address) string {
 	s := strings.ToLower(addr.String())
 	if len(s) > 32 {
@@ -90,13 +90,15 @@ func (st *IrohaState) GetAccount(address crypto.Address) (*acm.Account, error) {
 	return account, err
 }
 
-// mock
+// mock: this type is dead (see this package's doc comment in vm_state.go);
+// the live evm.IrohaState.GetMetadata/SetMetadata are now real, backed by
+// iroha.IrohaStorage's own Iroha_GetMetadata/Iroha_SetMetadata.
 func (st *IrohaState) GetMetadata(metahash acmstate.MetadataHash) (string, error) {
 	fmt.Printf("[GetMetadata] metahash: %s\n", metahash.String())
 	return "", nil
 }
 
-// mock
+// mock: see GetMetadata above.
 func (st *IrohaState) SetMetadata(metahash acmstate.MetadataHash, metadata string) error {
 	fmt.Printf("[SetMetadata] metahash: %s, metadata: %s\n", metahash.String(), metadata)
 	return nil
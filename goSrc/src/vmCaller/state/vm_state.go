@@ -1,3 +1,14 @@
+// Package state holds State, the original fixed-address/magic-storage-slot
+// dispatch this bridge used to reach Iroha commands from the EVM before
+// evm.MustCreateNatives's ServiceContract (see evm/native_contract.go)
+// replaced it with a real ABI-driven precompile registry: one Solidity-style
+// native.Function per Iroha command, selector-dispatched and reflection-typed
+// against Go argument/return structs, registered at a single address checked
+// by evm.IsNative. State/GetStorage is kept only as the legacy adapter for
+// contracts compiled against the old four fixed addresses
+// (assetBalanceAddress et al.) below; it is not wired into main.go's
+// EngineWrapper or execution/execution.go's CallSim path, and new precompiles
+// belong in evm/native_contract.go's ServiceContract, not here.
 package state
 
 import (
@@ -0,0 +1,167 @@
+package state_store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// storageKey flattens an (address, key) pair into a map key, the same shape
+// acmstate's own in-memory test backends use for a two-dimensional store.
+type storageKey struct {
+	address crypto.Address
+	key     binary.Word256
+}
+
+// snapshotFrame is the copy of every map MockStateStore.Snapshot records
+// (the maps are copied; the *acm.Account and []byte values they hold are
+// shared, not cloned), so a later RevertToSnapshot can restore exactly this state and a
+// later CommitSnapshot can simply discard it - mirroring the
+// snapshot/id/revert/commit semantics iroha.IrohaStorage documents against
+// real Ametsuchi snapshots (see commit 610ce8b's nested sub-call rollback
+// work), without needing Ametsuchi to do it.
+type snapshotFrame struct {
+	accounts map[crypto.Address]*acm.Account
+	storage  map[storageKey][]byte
+	metadata map[acmstate.MetadataHash]string
+}
+
+// MockStateStore is an in-memory StateStore, so evm.IrohaState (and anything
+// built against just the StateStore interface) can be exercised in Go-only
+// tests without a real Ametsuchi/irohad instance behind it. It is guarded by
+// a single mutex rather than finer-grained locking since it exists for test
+// determinism, not production throughput.
+type MockStateStore struct {
+	mtx         sync.Mutex
+	accounts    map[crypto.Address]*acm.Account
+	storage     map[storageKey][]byte
+	metadata    map[acmstate.MetadataHash]string
+	snapshots   map[uint64]snapshotFrame
+	nextSnapsID uint64
+}
+
+// NewMockStateStore returns an empty MockStateStore ready for use.
+func NewMockStateStore() *MockStateStore {
+	return &MockStateStore{
+		accounts:  map[crypto.Address]*acm.Account{},
+		storage:   map[storageKey][]byte{},
+		metadata:  map[acmstate.MetadataHash]string{},
+		snapshots: map[uint64]snapshotFrame{},
+	}
+}
+
+func (m *MockStateStore) GetAccount(address crypto.Address) (*acm.Account, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.accounts[address], nil
+}
+
+func (m *MockStateStore) UpdateAccount(account *acm.Account) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.accounts[account.Address] = account
+	return nil
+}
+
+func (m *MockStateStore) RemoveAccount(address crypto.Address) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.accounts, address)
+	return nil
+}
+
+func (m *MockStateStore) GetStorage(address crypto.Address, key binary.Word256) ([]byte, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.storage[storageKey{address, key}], nil
+}
+
+func (m *MockStateStore) SetStorage(address crypto.Address, key binary.Word256, value []byte) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.storage[storageKey{address, key}] = value
+	return nil
+}
+
+func (m *MockStateStore) GetMetadata(metahash acmstate.MetadataHash) (string, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.metadata[metahash], nil
+}
+
+func (m *MockStateStore) SetMetadata(metahash acmstate.MetadataHash, metadata string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.metadata[metahash] = metadata
+	return nil
+}
+
+// Snapshot copies every map (entry values are shared, not cloned) and files
+// the copies away under a freshly
+// allocated id, the way iroha.IrohaStorage.Snapshot hands back an id for a
+// real Ametsuchi snapshot taken at this point.
+func (m *MockStateStore) Snapshot() (uint64, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.nextSnapsID++
+	id := m.nextSnapsID
+
+	accounts := make(map[crypto.Address]*acm.Account, len(m.accounts))
+	for k, v := range m.accounts {
+		accounts[k] = v
+	}
+	storage := make(map[storageKey][]byte, len(m.storage))
+	for k, v := range m.storage {
+		storage[k] = v
+	}
+	metadata := make(map[acmstate.MetadataHash]string, len(m.metadata))
+	for k, v := range m.metadata {
+		metadata[k] = v
+	}
+
+	m.snapshots[id] = snapshotFrame{accounts: accounts, storage: storage, metadata: metadata}
+	return id, nil
+}
+
+// RevertToSnapshot restores the maps to exactly the state Snapshot(id)
+// recorded, and drops the frame afterward - a reverted snapshot cannot be
+// reverted to twice, matching iroha.IrohaStorage's own revert-once contract.
+func (m *MockStateStore) RevertToSnapshot(id uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	frame, ok := m.snapshots[id]
+	if !ok {
+		return fmt.Errorf("state_store: no snapshot with id %d", id)
+	}
+	m.accounts = frame.accounts
+	m.storage = frame.storage
+	m.metadata = frame.metadata
+	delete(m.snapshots, id)
+	return nil
+}
+
+// CommitSnapshot discards the recorded frame without touching current state,
+// the way committing a real Ametsuchi snapshot just releases it.
+func (m *MockStateStore) CommitSnapshot(id uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.snapshots[id]; !ok {
+		return fmt.Errorf("state_store: no snapshot with id %d", id)
+	}
+	delete(m.snapshots, id)
+	return nil
+}
+
+// StoreTxReceipt is a no-op recording stub: MockStateStore exists to back
+// account/storage/metadata assertions in tests, not to verify log
+// persistence, so unlike the rest of StateStore it keeps no state here.
+func (m *MockStateStore) StoreTxReceipt(address crypto.Address, hexData []byte, topics []binary.Word256) error {
+	return nil
+}
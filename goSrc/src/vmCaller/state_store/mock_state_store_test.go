@@ -0,0 +1,96 @@
+package state_store
+
+import (
+	"testing"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockStateStoreAccountRoundTrip(t *testing.T) {
+	store := NewMockStateStore()
+	address := crypto.Address{1}
+
+	got, err := store.GetAccount(address)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	account := &acm.Account{Address: address, Balance: 100}
+	require.NoError(t, store.UpdateAccount(account))
+
+	got, err = store.GetAccount(address)
+	require.NoError(t, err)
+	assert.Equal(t, account, got)
+
+	require.NoError(t, store.RemoveAccount(address))
+	got, err = store.GetAccount(address)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMockStateStoreStorageRoundTrip(t *testing.T) {
+	store := NewMockStateStore()
+	address := crypto.Address{2}
+	key := binary.LeftPadWord256([]byte("key"))
+
+	require.NoError(t, store.SetStorage(address, key, []byte("value")))
+	got, err := store.GetStorage(address, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), got)
+}
+
+func TestMockStateStoreMetadataRoundTrip(t *testing.T) {
+	store := NewMockStateStore()
+	var metahash acmstate.MetadataHash
+	copy(metahash[:], []byte("hash"))
+
+	require.NoError(t, store.SetMetadata(metahash, "metadata"))
+	got, err := store.GetMetadata(metahash)
+	require.NoError(t, err)
+	assert.Equal(t, "metadata", got)
+}
+
+func TestMockStateStoreRevertToSnapshot(t *testing.T) {
+	store := NewMockStateStore()
+	address := crypto.Address{3}
+	require.NoError(t, store.UpdateAccount(&acm.Account{Address: address, Balance: 1}))
+
+	id, err := store.Snapshot()
+	require.NoError(t, err)
+
+	require.NoError(t, store.UpdateAccount(&acm.Account{Address: address, Balance: 2}))
+	got, err := store.GetAccount(address)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, got.Balance)
+
+	require.NoError(t, store.RevertToSnapshot(id))
+	got, err = store.GetAccount(address)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, got.Balance)
+
+	// A reverted snapshot id cannot be reverted to twice.
+	assert.Error(t, store.RevertToSnapshot(id))
+}
+
+func TestMockStateStoreCommitSnapshot(t *testing.T) {
+	store := NewMockStateStore()
+	address := crypto.Address{4}
+	require.NoError(t, store.UpdateAccount(&acm.Account{Address: address, Balance: 1}))
+
+	id, err := store.Snapshot()
+	require.NoError(t, err)
+
+	require.NoError(t, store.UpdateAccount(&acm.Account{Address: address, Balance: 2}))
+	require.NoError(t, store.CommitSnapshot(id))
+
+	got, err := store.GetAccount(address)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, got.Balance)
+
+	// A committed snapshot id is released, not retained for later revert.
+	assert.Error(t, store.RevertToSnapshot(id))
+}
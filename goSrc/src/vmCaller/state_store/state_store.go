@@ -0,0 +1,37 @@
+// Package state_store defines StateStore, the storage-backend interface
+// evm.IrohaState (see evm/storage_state.go) depends on instead of
+// iroha.IrohaStorage directly, so a backend other than real cgo-backed
+// Ametsuchi storage - an in-memory mock for tests, a caching layer, a
+// RocksDB shadow copy - can stand in for it without evm needing to know the
+// difference. This package deliberately has no cgo of its own, so
+// MockStateStore (see mock_state_store.go) and anything built against just
+// this interface can be exercised in Go-only CI without the irohad/ C++ tree
+// iroha.IrohaStorage's cgo preamble requires. iroha.IrohaStorage satisfies
+// StateStore structurally - neither package imports the other for that.
+package state_store
+
+import (
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// StateStore is the subset of iroha.IrohaStorage's methods evm.IrohaState
+// needs: the acmstate.ReaderWriter account/storage operations, the
+// dedicated-column-family metadata pair, the snapshot/revert/commit frame
+// iroha.IrohaStorage's own doc comments describe, and StoreTxReceipt for log
+// persistence.
+type StateStore interface {
+	GetAccount(address crypto.Address) (*acm.Account, error)
+	UpdateAccount(account *acm.Account) error
+	RemoveAccount(address crypto.Address) error
+	GetStorage(address crypto.Address, key binary.Word256) ([]byte, error)
+	SetStorage(address crypto.Address, key binary.Word256, value []byte) error
+	GetMetadata(metahash acmstate.MetadataHash) (string, error)
+	SetMetadata(metahash acmstate.MetadataHash, metadata string) error
+	Snapshot() (uint64, error)
+	RevertToSnapshot(id uint64) error
+	CommitSnapshot(id uint64) error
+	StoreTxReceipt(address crypto.Address, hexData []byte, topics []binary.Word256) error
+}
@@ -0,0 +1,121 @@
+package main
+
+//typedef const char const_char;
+import "C"
+import (
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	"vmCaller/blockchain"
+	vm "vmCaller/evm"
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/acm/acmstate"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/engine"
+	"github.com/hyperledger/burrow/execution/exec"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/tmthrgd/go-hex"
+)
+
+// MaxCallGas is the gas limit given to a static call when none is supplied,
+// mirroring the generous allowance Ethereum nodes give eth_call.
+const MaxCallGas uint64 = 1 << 50
+
+// InflatedBalance is the balance a static call's caller is given so it
+// can probe value-sending code paths without owning real funds, mirroring
+// how eth_call inflates the caller's balance rather than rejecting the call.
+const InflatedBalance uint64 = 1 << 62
+
+//export VmCallStatic
+func VmCallStatic(input, caller, callee, gas, value *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+	iroha.Caller = C.GoString(caller)
+
+	// A copy-on-write cache over the real Iroha-backed state: every read
+	// falls through to live state, but writes are buffered here and
+	// discarded when the call returns, so a static call can never produce
+	// an Iroha command.
+	cache := acmstate.NewCache(vm.NewIrohaState(storage))
+
+	evmCaller := native.AddressFromName(C.GoString(caller))
+	if err := cache.UpdateAccount(&acm.Account{
+		Address:     evmCaller,
+		Balance:     InflatedBalance,
+		Permissions: vm.DefaultAccountPermissions,
+	}); err != nil {
+		return makeError(fmt.Sprintf("Error inflating caller balance for %s: %s", evmCaller.String(), err.Error()))
+	}
+
+	evmCallee, err := crypto.AddressFromHexString(C.GoString(callee))
+	if err != nil {
+		return makeError("Invalid callee address")
+	}
+
+	wrapper := EngineWrapper{
+		engine:    burrowEVM,
+		state:     cache,
+		eventSink: exec.NewNoopEventSink(),
+	}
+
+	output, err := wrapper.CallStatic(evmCaller, evmCallee, hex.MustDecodeString(C.GoString(input)),
+		optionalGas(C.GoString(gas)), optionalValue(C.GoString(value)))
+	if err != nil {
+		return makeError(err.Error())
+	}
+	return C.CString(hex.EncodeToString(output)), nil
+}
+
+func optionalGas(s string) uint64 {
+	if s == "" {
+		return MaxCallGas
+	}
+	gas, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return MaxCallGas
+	}
+	return gas
+}
+
+func optionalValue(s string) uint64 {
+	if s == "" {
+		return 0
+	}
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// CallStatic runs callee's code against a copy-on-write view of w.state
+// (already wrapping the real IrohaState in an acmstate.Cache that discards
+// its writes), without requiring callee to already exist, so it can probe
+// arbitrary addresses the way eth_call does.
+func (w *EngineWrapper) CallStatic(caller, callee crypto.Address, input []byte, gas, value uint64) ([]byte, error) {
+	calleeAccount, err := w.state.GetAccount(callee)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting account at address %s: %s", callee.String(), err.Error())
+	}
+
+	var code []byte
+	if calleeAccount != nil {
+		code = calleeAccount.EVMCode
+	}
+
+	params := engine.CallParams{
+		Caller: caller,
+		Callee: callee,
+		Input:  input,
+		Value:  value,
+		Gas:    &gas,
+	}
+	output, err := w.engine.Execute(w.state, blockchain.Instance(), w.eventSink, params, code)
+	if err != nil {
+		return nil, fmt.Errorf("Error statically calling %s: %s", callee.String(), err.Error())
+	}
+	return output, nil
+}
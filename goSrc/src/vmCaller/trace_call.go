@@ -0,0 +1,77 @@
+package main
+
+//typedef const char const_char;
+import "C"
+import (
+	"encoding/json"
+	"unsafe"
+
+	"vmCaller/blockchain"
+	vm "vmCaller/evm"
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/engine"
+	"github.com/hyperledger/burrow/execution/native"
+	"github.com/tmthrgd/go-hex"
+)
+
+// VmCallTraced runs callee's code the way VmCall does, but returns a
+// debug_traceTransaction-style trace alongside the output instead of
+// producing Iroha commands. tracerMode selects "structLogger" (the
+// default, go-ethereum's classic structLogs array) or "callTracer" (the
+// flat call-frame list, see vm.IrohaEventSink.CallFrames). StructLogs is
+// always present and always empty (see evm/trace.go's StructLog); Calls is
+// filled only in "callTracer" mode, though the sink collects the frames
+// regardless of mode.
+//
+//export VmCallTraced
+func VmCallTraced(input, caller, callee, gasLimit, tracerMode *C.const_char, commandExecutor, queryExecutor, storage unsafe.Pointer) (*C.char, *C.char) {
+	iroha.IrohaCommandExecutor = commandExecutor
+	iroha.IrohaQueryExecutor = queryExecutor
+	iroha.Caller = C.GoString(caller)
+
+	worldState := vm.NewIrohaState(storage)
+	evmCaller := native.AddressFromName(C.GoString(caller))
+	evmCallee, err := crypto.AddressFromHexString(C.GoString(callee))
+	if err != nil {
+		return makeError("Invalid callee address")
+	}
+
+	calleeAccount, err := worldState.GetAccount(evmCallee)
+	if err != nil {
+		return makeError(err.Error())
+	}
+	var code []byte
+	if calleeAccount != nil {
+		code = calleeAccount.EVMCode
+	}
+
+	gas := optionalGas(C.GoString(gasLimit))
+	sink := vm.NewIrohaEventSink(worldState)
+	params := engine.CallParams{
+		Caller: evmCaller,
+		Callee: evmCallee,
+		Input:  hex.MustDecodeString(C.GoString(input)),
+		Value:  0,
+		Gas:    &gas,
+	}
+
+	output, execErr := burrowEVM.Execute(worldState, blockchain.Instance(), sink, params, code)
+
+	result := vm.TraceResult{
+		StructLogs:  []vm.StructLog{},
+		Gas:         gas,
+		Failed:      execErr != nil,
+		ReturnValue: hex.EncodeToString(output),
+	}
+	if C.GoString(tracerMode) == "callTracer" {
+		result.Calls = sink.CallFrames()
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return makeError(err.Error())
+	}
+	return C.CString(string(encoded)), nil
+}